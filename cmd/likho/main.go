@@ -4,11 +4,13 @@ import (
 	"log"
 	"os"
 
+	"github.com/intothevoid/likho/internal/comments"
 	"github.com/intothevoid/likho/internal/config"
 	"github.com/intothevoid/likho/internal/generator"
 	"github.com/intothevoid/likho/internal/page"
 	"github.com/intothevoid/likho/internal/post"
 	"github.com/intothevoid/likho/internal/server"
+	"github.com/intothevoid/likho/internal/stats"
 	"github.com/intothevoid/likho/pkg/utils"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -34,6 +36,8 @@ func main() {
 	rootCmd.AddCommand(createCmd(cfg))
 	rootCmd.AddCommand(generateCmd(cfg))
 	rootCmd.AddCommand(serveCmd(cfg))
+	rootCmd.AddCommand(comments.CommentsCmd(cfg))
+	rootCmd.AddCommand(stats.StatsCmd(cfg))
 
 	if err := rootCmd.Execute(); err != nil {
 		logger.Error("error executing command", zap.Error(err))
@@ -58,7 +62,10 @@ func generateCmd(cfg *config.Config) *cobra.Command {
 		Use:   "generate",
 		Short: "Generate HTML files from markdown",
 		Run: func(cmd *cobra.Command, args []string) {
-			generator.Generate(cfg)
+			if err := generator.Generate(cfg); err != nil {
+				utils.GetLogger().Error("error generating site", zap.Error(err))
+				os.Exit(1)
+			}
 		},
 	}
 }
@@ -0,0 +1,70 @@
+// Package assets bundles the third-party front-end libraries used by the
+// themes so that generated sites work offline and without external CDNs.
+package assets
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+)
+
+//go:embed all:vendor
+var vendorFS embed.FS
+
+// Library describes a vendored front-end library and the files a page needs
+// to include in order to use it
+type Library struct {
+	Name    string
+	Version string
+	Styles  []string
+	Scripts []string
+}
+
+// Prism provides client-side syntax highlighting. The autoloader plugin
+// resolves language components relative to its own URL, so only the core
+// and the plugin have to be included on a page.
+var Prism = Library{
+	Name:    "prism",
+	Version: "1.29.0",
+	Styles:  []string{"prism/themes/prism.min.css"},
+	Scripts: []string{
+		"prism/components/prism-core.min.js",
+		"prism/plugins/autoloader/prism-autoloader.min.js",
+	},
+}
+
+// Mermaid renders diagrams from ```mermaid code fences
+var Mermaid = Library{
+	Name:    "mermaid",
+	Version: "10.9.1",
+	Scripts: []string{
+		"mermaid/mermaid.min.js",
+		"likho/mermaid-init.js",
+	},
+}
+
+// Libraries returns all vendored libraries
+func Libraries() []Library {
+	return []Library{Prism, Mermaid}
+}
+
+// Files returns the paths of all vendored files, relative to the vendor root
+func Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(vendorFS, "vendor", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Base(p) == "README.md" {
+			return nil
+		}
+		files = append(files, p[len("vendor/"):])
+		return nil
+	})
+	return files, err
+}
+
+// ReadFile returns the contents of a vendored file
+func ReadFile(name string) ([]byte, error) {
+	return vendorFS.ReadFile(path.Join("vendor", name))
+}
@@ -0,0 +1,53 @@
+package assets
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// vendorURL matches the vendored files templates link to
+var vendorURL = regexp.MustCompile(`/vendor/([A-Za-z0-9_./-]+\.(?:js|css))`)
+
+func TestLibraryFilesEmbedded(t *testing.T) {
+	for _, lib := range Libraries() {
+		for _, name := range append(append([]string(nil), lib.Styles...), lib.Scripts...) {
+			data, err := ReadFile(name)
+			if assert.NoError(t, err, "%s %s: %s is not vendored, run scripts/vendor_assets.sh", lib.Name, lib.Version, name) {
+				assert.NotEmpty(t, data, name)
+			}
+		}
+	}
+}
+
+func TestTemplateVendorFilesEmbedded(t *testing.T) {
+	files, err := Files()
+	assert.NoError(t, err)
+	embedded := make(map[string]bool, len(files))
+	for _, f := range files {
+		embedded[f] = true
+	}
+
+	// The built-in templates and the themes shipped with likho
+	for _, dir := range []string{"../generator/templates", "../../themes"} {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(p, ".html") {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			for _, m := range vendorURL.FindAllStringSubmatch(string(data), -1) {
+				assert.True(t, embedded[m[1]], "%s links to /vendor/%s, which is not vendored", p, m[1])
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package authors reads the authors data file, a YAML map from the IDs
+// posts name in their front matter to the authors' profiles.
+package authors
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v1"
+)
+
+// Author is a person writing posts
+type Author struct {
+	// ID is the author's key in the data file
+	ID     string
+	Name   string `yaml:"name"`
+	Bio    string `yaml:"bio"`
+	Avatar string `yaml:"avatar"`
+	Links  []Link `yaml:"links"`
+}
+
+// Link is one of an author's profiles elsewhere, such as a website or a
+// social network
+type Link struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// Load reads the authors in a data file, sorted by ID. Authors without a
+// name are named by their ID. A missing file has no authors.
+func Load(path string) ([]Author, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading authors: %v", err)
+	}
+
+	var byID map[string]Author
+	if err := yaml.Unmarshal(data, &byID); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	authors := make([]Author, 0, len(byID))
+	for id, a := range byID {
+		a.ID = id
+		a.Name = strings.TrimSpace(a.Name)
+		if a.Name == "" {
+			a.Name = id
+		}
+		a.Bio = strings.TrimSpace(a.Bio)
+		authors = append(authors, a)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		return authors[i].ID < authors[j].ID
+	})
+	return authors, nil
+}
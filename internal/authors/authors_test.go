@@ -0,0 +1,51 @@
+package authors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authors.yaml")
+	data := `jane:
+  name: "Jane Doe"
+  bio: |
+    Writes about Go.
+  avatar: "/images/jane.jpg"
+  links:
+    - name: "GitHub"
+      url: "https://github.com/jane"
+bob: {}
+`
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	authors, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Author{
+		{ID: "bob", Name: "bob"},
+		{
+			ID:     "jane",
+			Name:   "Jane Doe",
+			Bio:    "Writes about Go.",
+			Avatar: "/images/jane.jpg",
+			Links:  []Link{{Name: "GitHub", URL: "https://github.com/jane"}},
+		},
+	}, authors)
+}
+
+func TestLoadMissing(t *testing.T) {
+	authors, err := Load(filepath.Join(t.TempDir(), "authors.yaml"))
+	assert.NoError(t, err)
+	assert.Empty(t, authors)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authors.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("jane: [not, an, author"), 0644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
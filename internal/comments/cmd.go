@@ -0,0 +1,93 @@
+package comments
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Dir returns the directory holding the self-hosted comments
+func Dir(cfg *config.Config) string {
+	return filepath.Join(cfg.Content.SourceDir, cfg.Comments.SelfHosted.Dir)
+}
+
+// CommentsCmd returns the "comments" command used to moderate comments
+// submitted to the self-hosted endpoint
+func CommentsCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "comments",
+		Short: "Moderate self-hosted comments",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List comments awaiting moderation",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pending, err := NewStore(Dir(cfg)).Pending()
+			if err != nil {
+				return err
+			}
+			if len(pending) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No comments awaiting moderation.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tPOST\tDATE\tNAME\tCOMMENT")
+			for _, c := range pending {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ID, c.Post,
+					c.Date.Local().Format("2006-01-02 15:04"), c.Name, excerpt(c.Body, 60))
+			}
+			return w.Flush()
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "approve <id>...",
+		Short: "Approve comments so they are published on the next generate",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := NewStore(Dir(cfg))
+			for _, id := range args {
+				c, err := store.Approve(id)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "approved %s on %s\n", c.ID, c.Post)
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "reject <id>...",
+		Short: "Reject and delete comments",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := NewStore(Dir(cfg))
+			for _, id := range args {
+				c, err := store.Reject(id)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "rejected %s on %s\n", c.ID, c.Post)
+			}
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// excerpt returns the first n runes of s on a single line
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
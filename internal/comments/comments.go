@@ -0,0 +1,240 @@
+// Package comments stores comments submitted to the self-hosted comments
+// endpoint. Each comment is a YAML file under <dir>/<post>/<id>.yaml and
+// starts out pending until it is approved with "likho comments approve".
+package comments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v1"
+)
+
+// Comment statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+)
+
+// Comment is a single comment on a post
+type Comment struct {
+	ID     string
+	Post   string
+	Name   string
+	Body   string
+	Date   time.Time
+	Status string
+}
+
+// commentFile is the on-disk representation of a comment
+type commentFile struct {
+	Name   string `yaml:"name"`
+	Body   string `yaml:"body"`
+	Date   string `yaml:"date"`
+	Status string `yaml:"status"`
+}
+
+// Paragraphs splits the comment body on blank lines
+func (c Comment) Paragraphs() []string {
+	var paras []string
+	for _, p := range strings.Split(strings.ReplaceAll(c.Body, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paras = append(paras, p)
+		}
+	}
+	return paras
+}
+
+// Store reads and writes comments below a directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore creates a store rooted at dir, usually content/comments
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Add saves a new pending comment on post and returns it with its ID and
+// date set
+func (s *Store) Add(post, name, body string) (Comment, error) {
+	if !validName(post) {
+		return Comment{}, fmt.Errorf("invalid post id %q", post)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return Comment{}, fmt.Errorf("failed to generate comment id: %v", err)
+	}
+	now := time.Now().UTC()
+	c := Comment{
+		ID:     now.Format("20060102T150405") + "-" + hex.EncodeToString(suffix),
+		Post:   post,
+		Name:   name,
+		Body:   body,
+		Date:   now,
+		Status: StatusPending,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Join(s.dir, post), 0755); err != nil {
+		return Comment{}, fmt.Errorf("failed to create comments directory: %v", err)
+	}
+	return c, s.write(c)
+}
+
+// Approved returns the approved comments on post, oldest first
+func (s *Store) Approved(post string) ([]Comment, error) {
+	all, err := s.read(filepath.Join(s.dir, post, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	return filter(all, StatusApproved), nil
+}
+
+// Pending returns the comments awaiting moderation on all posts, oldest first
+func (s *Store) Pending() ([]Comment, error) {
+	all, err := s.read(filepath.Join(s.dir, "*", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	return filter(all, StatusPending), nil
+}
+
+// Approve marks the comment with the given ID as approved
+func (s *Store) Approve(id string) (Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.find(id)
+	if err != nil {
+		return Comment{}, err
+	}
+	c.Status = StatusApproved
+	return c, s.write(c)
+}
+
+// Reject deletes the comment with the given ID
+func (s *Store) Reject(id string) (Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.find(id)
+	if err != nil {
+		return Comment{}, err
+	}
+	if err := os.Remove(s.path(c)); err != nil {
+		return Comment{}, fmt.Errorf("failed to delete comment %s: %v", id, err)
+	}
+	return c, nil
+}
+
+func (s *Store) find(id string) (Comment, error) {
+	if !validName(id) {
+		return Comment{}, fmt.Errorf("invalid comment id %q", id)
+	}
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*", id+".yaml"))
+	if err != nil {
+		return Comment{}, err
+	}
+	if len(matches) == 0 {
+		return Comment{}, fmt.Errorf("comment %s not found", id)
+	}
+	return readComment(matches[0])
+}
+
+func (s *Store) read(pattern string) ([]Comment, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	comments := make([]Comment, 0, len(matches))
+	for _, path := range matches {
+		c, err := readComment(path)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].Date.Equal(comments[j].Date) {
+			return comments[i].Date.Before(comments[j].Date)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments, nil
+}
+
+// write saves c through a temporary file so readers never see a partial file
+func (s *Store) write(c Comment) error {
+	data, err := yaml.Marshal(commentFile{
+		Name:   c.Name,
+		Body:   c.Body,
+		Date:   c.Date.Format(time.RFC3339),
+		Status: c.Status,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode comment %s: %v", c.ID, err)
+	}
+	path := s.path(c)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write comment %s: %v", c.ID, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write comment %s: %v", c.ID, err)
+	}
+	return nil
+}
+
+func (s *Store) path(c Comment) string {
+	return filepath.Join(s.dir, c.Post, c.ID+".yaml")
+}
+
+func readComment(path string) (Comment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Comment{}, fmt.Errorf("failed to read comment %s: %v", path, err)
+	}
+	var f commentFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return Comment{}, fmt.Errorf("failed to parse comment %s: %v", path, err)
+	}
+	date, err := time.Parse(time.RFC3339, f.Date)
+	if err != nil {
+		return Comment{}, fmt.Errorf("invalid date in comment %s: %v", path, err)
+	}
+	return Comment{
+		ID:     strings.TrimSuffix(filepath.Base(path), ".yaml"),
+		Post:   filepath.Base(filepath.Dir(path)),
+		Name:   f.Name,
+		Body:   f.Body,
+		Date:   date,
+		Status: f.Status,
+	}, nil
+}
+
+func filter(comments []Comment, status string) []Comment {
+	var out []Comment
+	for _, c := range comments {
+		if c.Status == status {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// validName reports whether s is safe to use as a single path element
+func validName(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
@@ -0,0 +1,50 @@
+package comments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreModeration(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	first, err := store.Add("hello-2024-01-01", "Ann", "First line\n\nSecond: paragraph")
+	assert.NoError(t, err)
+	second, err := store.Add("other-2024-02-01", "Bob", "Hi")
+	assert.NoError(t, err)
+
+	pending, err := store.Pending()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	approved, err := store.Approve(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusApproved, approved.Status)
+
+	comments, err := store.Approved("hello-2024-01-01")
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, "Ann", comments[0].Name)
+		assert.Equal(t, first.Date.Unix(), comments[0].Date.Unix())
+		assert.Equal(t, []string{"First line", "Second: paragraph"}, comments[0].Paragraphs())
+	}
+
+	_, err = store.Reject(second.ID)
+	assert.NoError(t, err)
+	pending, err = store.Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = store.Approve(second.ID)
+	assert.Error(t, err)
+}
+
+func TestStoreRejectsPathTraversal(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	_, err := store.Add("../escape", "Ann", "Hi")
+	assert.Error(t, err)
+	_, err = store.Approve("../../etc/passwd")
+	assert.Error(t, err)
+}
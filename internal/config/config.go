@@ -2,21 +2,32 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config represents the configuration for the site
 type Config struct {
-	Site     SiteConfig     `mapstructure:"site"`
-	Content  ContentConfig  `mapstructure:"content"`
-	Theme    ThemeConfig    `mapstructure:"theme"`
-	Build    BuildConfig    `mapstructure:"build"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Social   SocialConfig   `mapstructure:"social"`
-	Features FeaturesConfig `mapstructure:"features"`
-	Custom   CustomConfig   `mapstructure:"custom"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
+	Site       SiteConfig                `mapstructure:"site"`
+	Author     string                    `mapstructure:"author"`
+	Content    ContentConfig             `mapstructure:"content"`
+	Theme      ThemeConfig               `mapstructure:"theme"`
+	Build      BuildConfig               `mapstructure:"build"`
+	Images     ImagesConfig              `mapstructure:"images"`
+	TOC        TOCConfig                 `mapstructure:"toc"`
+	Related    RelatedConfig             `mapstructure:"related"`
+	Tags       TagsConfig                `mapstructure:"tags"`
+	Taxonomies map[string]TaxonomyConfig `mapstructure:"taxonomies"`
+	Archive    ArchiveConfig             `mapstructure:"archive"`
+	Server     ServerConfig              `mapstructure:"server"`
+	Social     SocialConfig              `mapstructure:"social"`
+	Features   FeaturesConfig            `mapstructure:"features"`
+	RSS        RSSConfig                 `mapstructure:"rss"`
+	Comments   CommentsConfig            `mapstructure:"comments"`
+	Analytics  AnalyticsConfig           `mapstructure:"analytics"`
+	Custom     CustomConfig              `mapstructure:"custom"`
+	Logging    LoggingConfig             `mapstructure:"logging"`
 }
 
 // SiteConfig represents the site configuration
@@ -29,14 +40,18 @@ type SiteConfig struct {
 
 // ContentConfig represents the content configuration
 type ContentConfig struct {
-	SourceDir    string `mapstructure:"source_dir"`
-	PostsDir     string `mapstructure:"posts_dir"`
-	OutputDir    string `mapstructure:"output_dir"`
-	TemplatesDir string `mapstructure:"templates_dir"`
-	PagesDir     string `mapstructure:"pages_dir"`
-	PostsPerPage int    `mapstructure:"posts_per_page"`
-	ImagesDir    string `mapstructure:"images_dir"`
-	OtherDir     string `mapstructure:"other_dir"`
+	SourceDir      string `mapstructure:"source_dir"`
+	PostsDir       string `mapstructure:"posts_dir"`
+	OutputDir      string `mapstructure:"output_dir"`
+	TemplatesDir   string `mapstructure:"templates_dir"`
+	PagesDir       string `mapstructure:"pages_dir"`
+	PostsPerPage   int    `mapstructure:"posts_per_page"`
+	ImagesDir      string `mapstructure:"images_dir"`
+	OtherDir       string `mapstructure:"other_dir"`
+	GalleriesDir   string `mapstructure:"galleries_dir"`
+	SummaryWords   int    `mapstructure:"summary_words"`
+	WordsPerMinute int    `mapstructure:"words_per_minute"`
+	AuthorsFile    string `mapstructure:"authors_file"`
 }
 
 // ThemeConfig represents the theme configuration
@@ -61,14 +76,108 @@ type ThemeCustom struct {
 
 // BuildConfig represents the build configuration
 type BuildConfig struct {
-	Draft  bool `mapstructure:"draft"`
-	Future bool `mapstructure:"future"`
+	Draft              bool   `mapstructure:"draft"`
+	Future             bool   `mapstructure:"future"`
+	Minify             bool   `mapstructure:"minify"`
+	Precompress        bool   `mapstructure:"precompress"`
+	PrecompressMinSize int    `mapstructure:"precompress_min_size"`
+	OGImages           bool   `mapstructure:"og_images"`
+	CacheDir           string `mapstructure:"cache_dir"`
+}
+
+// ImagesConfig represents the image processing configuration
+type ImagesConfig struct {
+	Process  bool   `mapstructure:"process"`
+	Widths   []int  `mapstructure:"widths"`
+	MaxWidth int    `mapstructure:"max_width"`
+	Quality  int    `mapstructure:"quality"`
+	Sizes    string `mapstructure:"sizes"`
+}
+
+// TOCConfig represents the table of contents configuration for posts.
+// Headings from MinLevel to MaxLevel are listed.
+type TOCConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	MinLevel int  `mapstructure:"min_level"`
+	MaxLevel int  `mapstructure:"max_level"`
+}
+
+// RelatedConfig represents the related posts listed at the end of posts.
+// A Count of zero turns them off.
+type RelatedConfig struct {
+	Count   int            `mapstructure:"count"`
+	Weights RelatedWeights `mapstructure:"weights"`
+}
+
+// RelatedWeights scale the similarity of tags, titles and bodies when
+// scoring related posts
+type RelatedWeights struct {
+	Tags    float64 `mapstructure:"tags"`
+	Title   float64 `mapstructure:"title"`
+	Content float64 `mapstructure:"content"`
+}
+
+// TagsConfig represents the tags configuration. Aliases map other
+// spellings of a tag, such as "golang", to the name it is shown with.
+type TagsConfig struct {
+	Aliases map[string]string `mapstructure:"aliases"`
+}
+
+// TaxonomyConfig represents a taxonomy, such as categories, whose terms
+// posts list in their front matter under the taxonomy's key. Template and
+// IndexTemplate name the templates of its term pages and of its index page,
+// and Feed adds an RSS feed per term.
+type TaxonomyConfig struct {
+	Title         string `mapstructure:"title"`
+	Template      string `mapstructure:"template"`
+	IndexTemplate string `mapstructure:"index_template"`
+	Feed          bool   `mapstructure:"feed"`
+}
+
+// ArchiveConfig represents the date archives. The URL patterns of year and
+// month pages contain :year and :month placeholders; URLs ending in a slash
+// are written as index.html files.
+type ArchiveConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	URL      string `mapstructure:"url"`
+	YearURL  string `mapstructure:"year_url"`
+	MonthURL string `mapstructure:"month_url"`
 }
 
 // ServerConfig represents the server configuration
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Host string `mapstructure:"host"`
+	Port            int                `mapstructure:"port"`
+	Host            string             `mapstructure:"host"`
+	ReadTimeout     time.Duration      `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration      `mapstructure:"write_timeout"`
+	IdleTimeout     time.Duration      `mapstructure:"idle_timeout"`
+	ShutdownTimeout time.Duration      `mapstructure:"shutdown_timeout"`
+	Compress        bool               `mapstructure:"compress"`
+	AccessLog       bool               `mapstructure:"access_log"`
+	SearchAPI       bool               `mapstructure:"search_api"`
+	CacheControl    CacheControlConfig `mapstructure:"cache_control"`
+	Pageviews       PageviewsConfig    `mapstructure:"pageviews"`
+}
+
+// PageviewsConfig represents the cookieless pageview recording done by the
+// server
+type PageviewsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	File    string `mapstructure:"file"`
+}
+
+// CacheControlConfig represents the Cache-Control policies used by the server
+type CacheControlConfig struct {
+	Fingerprinted string             `mapstructure:"fingerprinted"`
+	HTML          string             `mapstructure:"html"`
+	Default       string             `mapstructure:"default"`
+	Paths         []CacheControlRule `mapstructure:"paths"`
+}
+
+// CacheControlRule sets the Cache-Control header for paths under a prefix
+type CacheControlRule struct {
+	Prefix string `mapstructure:"prefix"`
+	Value  string `mapstructure:"value"`
 }
 
 // SocialConfig represents the social media configuration
@@ -85,6 +194,79 @@ type FeaturesConfig struct {
 	RSS      bool `mapstructure:"rss"`
 }
 
+// RSSConfig represents the RSS feed configuration. Content is "full" to
+// include whole posts in the feed or "summary" for their summaries.
+type RSSConfig struct {
+	Content string `mapstructure:"content"`
+}
+
+// CommentsConfig represents the comments configuration. Comments are shown
+// on posts when features.comments is enabled.
+type CommentsConfig struct {
+	Provider   string           `mapstructure:"provider"`
+	Disqus     DisqusConfig     `mapstructure:"disqus"`
+	Giscus     GiscusConfig     `mapstructure:"giscus"`
+	Utterances UtterancesConfig `mapstructure:"utterances"`
+	SelfHosted SelfHostedConfig `mapstructure:"self_hosted"`
+}
+
+// DisqusConfig represents the Disqus comments configuration
+type DisqusConfig struct {
+	Shortname string `mapstructure:"shortname"`
+}
+
+// GiscusConfig represents the giscus (GitHub Discussions) comments configuration
+type GiscusConfig struct {
+	Repo       string `mapstructure:"repo"`
+	RepoID     string `mapstructure:"repo_id"`
+	Category   string `mapstructure:"category"`
+	CategoryID string `mapstructure:"category_id"`
+	Mapping    string `mapstructure:"mapping"`
+	Theme      string `mapstructure:"theme"`
+}
+
+// UtterancesConfig represents the utterances (GitHub Issues) comments configuration
+type UtterancesConfig struct {
+	Repo      string `mapstructure:"repo"`
+	IssueTerm string `mapstructure:"issue_term"`
+	Label     string `mapstructure:"label"`
+	Theme     string `mapstructure:"theme"`
+}
+
+// SelfHostedConfig represents the configuration for comments submitted to
+// the self-hosted endpoint served by "likho serve"
+type SelfHostedConfig struct {
+	Endpoint   string        `mapstructure:"endpoint"`
+	Dir        string        `mapstructure:"dir"`
+	RateLimit  int           `mapstructure:"rate_limit"`
+	RateWindow time.Duration `mapstructure:"rate_window"`
+}
+
+// AnalyticsConfig represents the third-party analytics configuration
+type AnalyticsConfig struct {
+	Provider    string            `mapstructure:"provider"`
+	GA4         GA4Config         `mapstructure:"ga4"`
+	Plausible   PlausibleConfig   `mapstructure:"plausible"`
+	GoatCounter GoatCounterConfig `mapstructure:"goatcounter"`
+}
+
+// GA4Config represents the Google Analytics 4 configuration
+type GA4Config struct {
+	MeasurementID string `mapstructure:"measurement_id"`
+}
+
+// PlausibleConfig represents the Plausible Analytics configuration
+type PlausibleConfig struct {
+	Domain string `mapstructure:"domain"`
+	Script string `mapstructure:"script"`
+}
+
+// GoatCounterConfig represents the GoatCounter configuration
+type GoatCounterConfig struct {
+	Code   string `mapstructure:"code"`
+	Script string `mapstructure:"script"`
+}
+
 // CustomConfig represents custom configuration
 type CustomConfig struct {
 	GoogleAnalytics string `mapstructure:"google_analytics"`
@@ -109,6 +291,9 @@ func Load() (*Config, error) {
 	v.SetDefault("site.base_url", "http://localhost:8080")
 	v.SetDefault("site.language", "en")
 
+	// Author defaults
+	v.SetDefault("author", "")
+
 	// Content defaults
 	v.SetDefault("content.source_dir", "content")
 	v.SetDefault("content.posts_dir", "posts")
@@ -118,6 +303,10 @@ func Load() (*Config, error) {
 	v.SetDefault("content.posts_per_page", 10)
 	v.SetDefault("content.images_dir", "images")
 	v.SetDefault("content.other_dir", "other")
+	v.SetDefault("content.galleries_dir", "galleries")
+	v.SetDefault("content.summary_words", 70)
+	v.SetDefault("content.words_per_minute", 200)
+	v.SetDefault("content.authors_file", "authors.yaml")
 
 	// Theme defaults
 	v.SetDefault("theme.name", "default")
@@ -130,10 +319,51 @@ func Load() (*Config, error) {
 	// Build defaults
 	v.SetDefault("build.draft", false)
 	v.SetDefault("build.future", false)
+	v.SetDefault("build.minify", false)
+	v.SetDefault("build.precompress", false)
+	v.SetDefault("build.precompress_min_size", 1024)
+	v.SetDefault("build.og_images", true)
+	v.SetDefault("build.cache_dir", ".likho-cache")
+
+	// Images defaults
+	v.SetDefault("images.process", true)
+	v.SetDefault("images.widths", []int{480, 800, 1200, 1600})
+	v.SetDefault("images.max_width", 2400)
+	v.SetDefault("images.quality", 82)
+	v.SetDefault("images.sizes", "(max-width: 800px) 100vw, 800px")
+
+	// TOC defaults
+	v.SetDefault("toc.enabled", false)
+	v.SetDefault("toc.min_level", 2)
+	v.SetDefault("toc.max_level", 3)
+
+	// Archive defaults
+	v.SetDefault("archive.enabled", true)
+	v.SetDefault("archive.url", "/archive/")
+	v.SetDefault("archive.year_url", "/:year/")
+	v.SetDefault("archive.month_url", "/:year/:month/")
+
+	// Related posts defaults
+	v.SetDefault("related.count", 3)
+	v.SetDefault("related.weights.tags", 1.0)
+	v.SetDefault("related.weights.title", 0.5)
+	v.SetDefault("related.weights.content", 1.0)
 
 	// Server defaults
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.host", "localhost")
+	v.SetDefault("server.read_timeout", "10s")
+	v.SetDefault("server.write_timeout", "30s")
+	v.SetDefault("server.idle_timeout", "120s")
+	v.SetDefault("server.shutdown_timeout", "10s")
+	v.SetDefault("server.compress", true)
+	v.SetDefault("server.access_log", true)
+	v.SetDefault("server.search_api", true)
+	v.SetDefault("server.cache_control.fingerprinted", "public, max-age=31536000, immutable")
+	v.SetDefault("server.cache_control.html", "no-cache")
+	v.SetDefault("server.cache_control.default", "public, max-age=3600")
+	v.SetDefault("server.pageviews.enabled", false)
+	v.SetDefault("server.pageviews.file", "pageviews.jsonl")
 
 	// Social defaults
 	v.SetDefault("social.twitter", "")
@@ -145,6 +375,26 @@ func Load() (*Config, error) {
 	v.SetDefault("features.search", true)
 	v.SetDefault("features.rss", true)
 
+	// RSS defaults
+	v.SetDefault("rss.content", "full")
+
+	// Comments defaults
+	v.SetDefault("comments.provider", "disqus")
+	v.SetDefault("comments.disqus.shortname", "")
+	v.SetDefault("comments.giscus.mapping", "pathname")
+	v.SetDefault("comments.giscus.theme", "preferred_color_scheme")
+	v.SetDefault("comments.utterances.issue_term", "pathname")
+	v.SetDefault("comments.utterances.theme", "github-light")
+	v.SetDefault("comments.self_hosted.endpoint", "/api/comments")
+	v.SetDefault("comments.self_hosted.dir", "comments")
+	v.SetDefault("comments.self_hosted.rate_limit", 5)
+	v.SetDefault("comments.self_hosted.rate_window", "10m")
+
+	// Analytics defaults
+	v.SetDefault("analytics.provider", "")
+	v.SetDefault("analytics.plausible.script", "https://plausible.io/js/script.js")
+	v.SetDefault("analytics.goatcounter.script", "https://gc.zgo.at/count.js")
+
 	// Custom defaults
 	v.SetDefault("custom.google_analytics", "")
 	v.SetDefault("custom.disqus_shortname", "")
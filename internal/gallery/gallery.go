@@ -0,0 +1,243 @@
+// Package gallery reads photo galleries: directories of images with an
+// optional index.md describing the gallery and YAML sidecar files holding
+// the captions of individual photos.
+package gallery
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/intothevoid/likho/internal/images"
+	"gopkg.in/yaml.v1"
+)
+
+// IndexFile is the optional Markdown file describing a gallery
+const IndexFile = "index.md"
+
+// exifReadSize is how much of a photo is read to find its EXIF block, which
+// sits near the start of a JPEG and is at most 64 KiB
+const exifReadSize = 128 << 10
+
+// Gallery is a directory of photos
+type Gallery struct {
+	// Name is the directory name, slugged for the gallery's URLs
+	Name        string
+	Title       string
+	Description string
+	Date        time.Time
+	// Content is the Markdown body of index.md
+	Content string
+	// Cover is the photo shown in listings
+	Cover  Photo
+	Photos []Photo
+}
+
+// Photo is an image in a gallery
+type Photo struct {
+	// File is the file name within the gallery directory
+	File string
+	// Name is the file name without its extension, used for the photo's page
+	Name    string
+	Title   string
+	Caption string
+	// Date is when the photo was taken, from its EXIF data or sidecar file
+	Date time.Time
+}
+
+// Meta is the front matter of a gallery's index.md
+type Meta struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Date        string `yaml:"date"`
+	Cover       string `yaml:"cover"`
+	// Sort orders the photos by "date" taken, the default, or by "name"
+	Sort string `yaml:"sort"`
+}
+
+// Sidecar is the content of a photo's sidecar file, photo.yaml for
+// photo.jpg
+type Sidecar struct {
+	Title   string `yaml:"title"`
+	Caption string `yaml:"caption"`
+	Date    string `yaml:"date"`
+}
+
+// IsPhoto reports whether a file name has an image extension handled by
+// galleries
+func IsPhoto(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+// Load reads every gallery in a directory, newest first. A missing
+// directory has no galleries.
+func Load(dir string) ([]Gallery, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading galleries: %v", err)
+	}
+
+	var galleries []Gallery
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		g, err := LoadGallery(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if len(g.Photos) > 0 {
+			galleries = append(galleries, g)
+		}
+	}
+	sort.SliceStable(galleries, func(i, j int) bool {
+		return galleries[i].Date.After(galleries[j].Date)
+	})
+	return galleries, nil
+}
+
+// LoadGallery reads the gallery in a directory
+func LoadGallery(dir string) (Gallery, error) {
+	g := Gallery{Name: filepath.Base(dir)}
+
+	var meta Meta
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err == nil {
+		parts := strings.SplitN(string(data), "---", 3)
+		if len(parts) != 3 {
+			return Gallery{}, fmt.Errorf("invalid gallery format: %s", filepath.Join(dir, IndexFile))
+		}
+		if err := yaml.Unmarshal([]byte(parts[1]), &meta); err != nil {
+			return Gallery{}, fmt.Errorf("error parsing frontmatter in %s: %v", filepath.Join(dir, IndexFile), err)
+		}
+		g.Content = parts[2]
+	} else if !os.IsNotExist(err) {
+		return Gallery{}, err
+	}
+
+	g.Title = meta.Title
+	if g.Title == "" {
+		g.Title = titleFromName(g.Name)
+	}
+	g.Description = meta.Description
+	if meta.Date != "" {
+		if g.Date, err = parseDate(meta.Date); err != nil {
+			return Gallery{}, fmt.Errorf("invalid date in %s: %v", filepath.Join(dir, IndexFile), err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Gallery{}, fmt.Errorf("error reading gallery %s: %v", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !IsPhoto(entry.Name()) {
+			continue
+		}
+		photo, err := loadPhoto(dir, entry.Name())
+		if err != nil {
+			return Gallery{}, err
+		}
+		g.Photos = append(g.Photos, photo)
+	}
+	if len(g.Photos) == 0 {
+		return g, nil
+	}
+
+	if meta.Sort != "name" {
+		// Photos without a date keep their name order after the others
+		sort.SliceStable(g.Photos, func(i, j int) bool {
+			a, b := g.Photos[i].Date, g.Photos[j].Date
+			return !a.IsZero() && (b.IsZero() || a.Before(b))
+		})
+	}
+
+	g.Cover = g.Photos[0]
+	for _, p := range g.Photos {
+		if p.File == meta.Cover {
+			g.Cover = p
+		}
+	}
+
+	// Without a date, a gallery is dated by its earliest photo
+	if g.Date.IsZero() {
+		for _, p := range g.Photos {
+			if !p.Date.IsZero() && (g.Date.IsZero() || p.Date.Before(g.Date)) {
+				g.Date = p.Date
+			}
+		}
+	}
+	return g, nil
+}
+
+// loadPhoto reads a photo's date and its sidecar file
+func loadPhoto(dir, file string) (Photo, error) {
+	p := Photo{File: file, Name: strings.TrimSuffix(file, filepath.Ext(file))}
+
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		return Photo{}, fmt.Errorf("error reading photo %s: %v", filepath.Join(dir, file), err)
+	}
+	head, err := io.ReadAll(io.LimitReader(f, exifReadSize))
+	f.Close()
+	if err != nil {
+		return Photo{}, fmt.Errorf("error reading photo %s: %v", filepath.Join(dir, file), err)
+	}
+	if exif, ok := images.ReadEXIF(head); ok {
+		p.Date = exif.DateTime
+	}
+
+	sidecarPath := filepath.Join(dir, p.Name+".yaml")
+	data, err := os.ReadFile(sidecarPath)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return Photo{}, fmt.Errorf("error reading %s: %v", sidecarPath, err)
+	}
+	var sidecar Sidecar
+	if err := yaml.Unmarshal(data, &sidecar); err != nil {
+		return Photo{}, fmt.Errorf("error parsing %s: %v", sidecarPath, err)
+	}
+	p.Title = sidecar.Title
+	p.Caption = strings.TrimSpace(sidecar.Caption)
+	if sidecar.Date != "" {
+		if p.Date, err = parseDate(sidecar.Date); err != nil {
+			return Photo{}, fmt.Errorf("invalid date in %s: %v", sidecarPath, err)
+		}
+	}
+	return p, nil
+}
+
+// parseDate parses a date in one of the formats accepted in front matter
+func parseDate(s string) (time.Time, error) {
+	var err error
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04", "January 2, 2006 15:04"} {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// titleFromName turns a directory name such as "iceland-2024" into a title
+func titleFromName(name string) string {
+	title := []rune(strings.NewReplacer("-", " ", "_", " ").Replace(name))
+	if len(title) > 0 {
+		title[0] = unicode.ToUpper(title[0])
+	}
+	return string(title)
+}
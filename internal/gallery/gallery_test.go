@@ -0,0 +1,101 @@
+package gallery
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jpegWithDate returns the start of a JPEG holding only an EXIF block with a
+// DateTime, which is all Load reads
+func jpegWithDate(date string) []byte {
+	order := binary.BigEndian
+	tiff := []byte("MM\x00*")
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, 0x0132)
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = order.AppendUint32(tiff, uint32(len(date)+1))
+	tiff = order.AppendUint32(tiff, 8+2+12+4)
+	tiff = order.AppendUint32(tiff, 0)
+	tiff = append(tiff, date+"\x00"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	data = order.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA)
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "iceland", "index.md"), []byte("---\ntitle: \"Iceland\"\ndescription: \"The ring road\"\ncover: \"b.jpg\"\n---\n\nA week on the road.\n"))
+	writeFile(t, filepath.Join(dir, "iceland", "a.jpg"), jpegWithDate("2024:06:02 10:00:00"))
+	writeFile(t, filepath.Join(dir, "iceland", "b.jpg"), jpegWithDate("2024:06:01 09:00:00"))
+	writeFile(t, filepath.Join(dir, "iceland", "b.yaml"), []byte("title: \"Skógafoss\"\ncaption: \"The falls at dawn\"\n"))
+	writeFile(t, filepath.Join(dir, "iceland", "c.png"), []byte("no exif"))
+	writeFile(t, filepath.Join(dir, "iceland", "notes.txt"), []byte("not a photo"))
+
+	writeFile(t, filepath.Join(dir, "old-photos", "x.jpg"), []byte("no exif"))
+	writeFile(t, filepath.Join(dir, "old-photos", "x.yaml"), []byte("date: 2020-05-01\n"))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+
+	galleries, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, galleries, 2)
+
+	g := galleries[0]
+	assert.Equal(t, "iceland", g.Name)
+	assert.Equal(t, "Iceland", g.Title)
+	assert.Equal(t, "The ring road", g.Description)
+	assert.Equal(t, "\n\nA week on the road.\n", g.Content)
+	// Dated by the earliest photo, which is sorted first; undated photos last
+	assert.Equal(t, time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), g.Date)
+	var files []string
+	for _, p := range g.Photos {
+		files = append(files, p.File)
+	}
+	assert.Equal(t, []string{"b.jpg", "a.jpg", "c.png"}, files)
+	assert.Equal(t, Photo{
+		File:    "b.jpg",
+		Name:    "b",
+		Title:   "Skógafoss",
+		Caption: "The falls at dawn",
+		Date:    time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+	}, g.Cover)
+
+	g = galleries[1]
+	assert.Equal(t, "Old photos", g.Title)
+	assert.Equal(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), g.Date)
+	assert.Equal(t, "x.jpg", g.Cover.File)
+}
+
+func TestLoadSortByName(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "trip", "index.md"), []byte("---\nsort: \"name\"\ndate: 2024-01-05\n---\n"))
+	writeFile(t, filepath.Join(dir, "trip", "1.jpg"), jpegWithDate("2024:06:02 10:00:00"))
+	writeFile(t, filepath.Join(dir, "trip", "2.jpg"), jpegWithDate("2024:06:01 10:00:00"))
+
+	g, err := LoadGallery(filepath.Join(dir, "trip"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.jpg", g.Photos[0].File)
+	assert.Equal(t, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), g.Date)
+}
+
+func TestLoadMissingDir(t *testing.T) {
+	galleries, err := Load(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+	assert.Empty(t, galleries)
+}
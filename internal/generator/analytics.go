@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/intothevoid/likho/internal/config"
+)
+
+// analyticsData holds the fields used by the analytics partial. ID is the
+// GA4 measurement ID, the Plausible domain or the GoatCounter endpoint.
+type analyticsData struct {
+	Provider string
+	ID       string
+	Script   string
+}
+
+// newAnalyticsData returns the analytics partial data, or nil when no
+// provider is configured
+func newAnalyticsData(cfg *config.Config) *analyticsData {
+	a := cfg.Analytics
+	switch a.Provider {
+	case "ga4":
+		id := a.GA4.MeasurementID
+		if id == "" {
+			id = cfg.Custom.GoogleAnalytics
+		}
+		if id == "" {
+			return nil
+		}
+		return &analyticsData{Provider: a.Provider, ID: id}
+	case "plausible":
+		domain := a.Plausible.Domain
+		if domain == "" {
+			if u, err := url.Parse(cfg.Site.BaseURL); err == nil {
+				domain = u.Hostname()
+			}
+		}
+		if domain == "" {
+			return nil
+		}
+		return &analyticsData{Provider: a.Provider, ID: domain, Script: a.Plausible.Script}
+	case "goatcounter":
+		endpoint := a.GoatCounter.Code
+		if endpoint == "" {
+			return nil
+		}
+		// A plain code refers to the hosted service, anything else is the
+		// URL of a self-hosted GoatCounter
+		if !strings.Contains(endpoint, "/") {
+			endpoint = "https://" + endpoint + ".goatcounter.com/count"
+		}
+		return &analyticsData{Provider: a.Provider, ID: endpoint, Script: a.GoatCounter.Script}
+	}
+	return nil
+}
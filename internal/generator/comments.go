@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/intothevoid/likho/internal/comments"
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// commentsData holds the fields used by the comments partial
+type commentsData struct {
+	Provider string
+	ID       string
+	URL      string
+	Title    string
+	Endpoint string
+	Config   config.CommentsConfig
+	Approved []comments.Comment
+}
+
+// newCommentsData returns the comments partial data for a post, or nil when
+// comments are disabled for the site or the post, or the provider is not
+// configured
+func newCommentsData(cfg *config.Config, p post.Post) *commentsData {
+	if !cfg.Features.Comments || p.DisableComments {
+		return nil
+	}
+	logger := utils.GetLogger()
+
+	c := &commentsData{
+		Provider: cfg.Comments.Provider,
+		ID:       PostID(p),
+		URL:      strings.TrimRight(cfg.Site.BaseURL, "/") + postURL(p),
+		Title:    p.Title,
+		Config:   cfg.Comments,
+	}
+
+	switch c.Provider {
+	case "disqus":
+		if c.Config.Disqus.Shortname == "" {
+			c.Config.Disqus.Shortname = cfg.Custom.DisqusShortname
+		}
+		if c.Config.Disqus.Shortname == "" {
+			logger.Warn("comments enabled but no disqus shortname configured")
+			return nil
+		}
+	case "giscus":
+		if c.Config.Giscus.Repo == "" || c.Config.Giscus.RepoID == "" || c.Config.Giscus.CategoryID == "" {
+			logger.Warn("comments enabled but giscus repo, repo_id or category_id not configured")
+			return nil
+		}
+	case "utterances":
+		if c.Config.Utterances.Repo == "" {
+			logger.Warn("comments enabled but no utterances repo configured")
+			return nil
+		}
+	case "self-hosted":
+		c.Endpoint = strings.TrimRight(c.Config.SelfHosted.Endpoint, "/") + "/" + c.ID
+		approved, err := comments.NewStore(comments.Dir(cfg)).Approved(c.ID)
+		if err != nil {
+			logger.Error("error reading comments", zap.String("post", c.ID), zap.Error(err))
+		}
+		c.Approved = approved
+	default:
+		logger.Warn("unknown comments provider", zap.String("provider", c.Provider))
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,363 @@
+package generator
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/intothevoid/likho/internal/images"
+)
+
+// gallerySizes is the sizes attribute of images in a gallery, matching the
+// grid columns of the themes
+const gallerySizes = "(max-width: 600px) 50vw, 300px"
+
+// alignments are the classes of an attribute block that align a figure.
+// They are rendered with an "align-" prefix.
+var alignments = map[string]bool{"left": true, "right": true, "center": true}
+
+// imageHook renders Markdown images as lazily loaded <img> tags, images
+// that stand alone in a paragraph with a title or attribute block as
+// <figure>, and "gallery" code fences as a grid of figures
+func imageHook(imgs *images.Processor) mdhtml.RenderNodeFunc {
+	return func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+		switch node := node.(type) {
+		case *ast.Image:
+			if entering {
+				io.WriteString(w, imageTag(node, imgs, true, ""))
+			}
+			return ast.SkipChildren, true
+		case *ast.Paragraph:
+			img := figureImage(node)
+			if img == nil {
+				return ast.GoToNext, false
+			}
+			if entering {
+				renderFigure(w, img, mergeAttributes(node.Attribute, img.Attribute), imgs)
+			}
+			return ast.SkipChildren, true
+		case *ast.CodeBlock:
+			if strings.TrimSpace(string(node.Info)) != "gallery" {
+				return ast.GoToNext, false
+			}
+			renderGallery(w, node, imgs)
+			return ast.GoToNext, true
+		}
+		return ast.GoToNext, false
+	}
+}
+
+// parseImageAttributes moves attribute blocks written directly after an
+// image, as in ![alt](photo.jpg){width=400 .center}, from the following
+// text into the image node
+func parseImageAttributes(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		img, ok := node.(*ast.Image)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		text, ok := ast.GetNextNode(img).(*ast.Text)
+		if !ok {
+			return ast.GoToNext
+		}
+		if attr, n := parseAttributeBlock(text.Literal); attr != nil {
+			img.Attribute = attr
+			text.Literal = text.Literal[n:]
+		}
+		return ast.GoToNext
+	})
+}
+
+// parseAttributeBlock parses an attribute block such as
+// {#id .class key=value key="quoted value"} at the start of data. It returns
+// the attributes and the length of the block, or nil if data does not start
+// with a valid block.
+func parseAttributeBlock(data []byte) (*ast.Attribute, int) {
+	if len(data) < 2 || data[0] != '{' {
+		return nil, 0
+	}
+
+	var fields []string
+	var field []byte
+	quoted := false
+	for i := 1; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			return nil, 0
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+			field = append(field, c)
+		case c == ' ' || c == '\t' || c == '}':
+			if len(field) > 0 {
+				fields = append(fields, string(field))
+				field = field[:0]
+			}
+			if c == '}' {
+				attr := parseAttributeFields(fields)
+				if attr == nil {
+					return nil, 0
+				}
+				return attr, i + 1
+			}
+		default:
+			field = append(field, c)
+		}
+	}
+	return nil, 0
+}
+
+// parseAttributeFields converts the fields of an attribute block, with
+// quotes removed, to an attribute
+func parseAttributeFields(fields []string) *ast.Attribute {
+	if len(fields) == 0 {
+		return nil
+	}
+	attr := &ast.Attribute{Attrs: make(map[string][]byte)}
+	for _, f := range fields {
+		switch {
+		case strings.HasPrefix(f, ".") && len(f) > 1:
+			attr.Classes = append(attr.Classes, []byte(f[1:]))
+		case strings.HasPrefix(f, "#") && len(f) > 1:
+			attr.ID = []byte(f[1:])
+		default:
+			key, value, ok := strings.Cut(f, "=")
+			if !ok || !validAttributeName(key) {
+				return nil
+			}
+			attr.Attrs[strings.ToLower(key)] = []byte(value)
+		}
+	}
+	return attr
+}
+
+// validAttributeName reports whether name can be written as an HTML
+// attribute name without escaping
+func validAttributeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeAttributes combines a paragraph's block attributes with those of
+// the image inside it. The image's attributes take precedence.
+func mergeAttributes(block, inline *ast.Attribute) *ast.Attribute {
+	if block == nil {
+		return inline
+	}
+	if inline == nil {
+		return block
+	}
+	attr := &ast.Attribute{ID: block.ID, Attrs: make(map[string][]byte)}
+	attr.Classes = append(append(attr.Classes, block.Classes...), inline.Classes...)
+	if inline.ID != nil {
+		attr.ID = inline.ID
+	}
+	for k, v := range block.Attrs {
+		attr.Attrs[k] = v
+	}
+	for k, v := range inline.Attrs {
+		attr.Attrs[k] = v
+	}
+	return attr
+}
+
+// figureImage returns the image of a paragraph that holds nothing else and
+// should be rendered as a figure, because the image has a caption or
+// attributes
+func figureImage(para *ast.Paragraph) *ast.Image {
+	var img *ast.Image
+	for _, child := range para.Children {
+		switch child := child.(type) {
+		case *ast.Image:
+			if img != nil {
+				return nil
+			}
+			img = child
+		case *ast.Text:
+			if len(bytes.TrimSpace(child.Literal)) > 0 {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	if img == nil || len(img.Title) == 0 && img.Attribute == nil && para.Attribute == nil {
+		return nil
+	}
+	return img
+}
+
+// renderFigure writes an image as a <figure> with its title as the caption.
+// The attribute block's ID and classes are set on the figure, its other
+// attributes on the image.
+func renderFigure(w io.Writer, img *ast.Image, attr *ast.Attribute, imgs *images.Processor) {
+	saved := img.Attribute
+	img.Attribute = attr
+	tag := imageTag(img, imgs, false, "")
+	img.Attribute = saved
+
+	io.WriteString(w, "<figure"+figureAttrs(attr, "")+">\n"+tag+"\n")
+	writeCaption(w, img)
+	io.WriteString(w, "</figure>\n")
+}
+
+// renderGallery writes the images listed in a "gallery" code fence as a
+// grid of figures linking to the full size images
+func renderGallery(w io.Writer, block *ast.CodeBlock, imgs *images.Processor) {
+	doc := markdown.Parse(block.Literal, newMarkdownParser())
+	parseImageAttributes(doc)
+
+	io.WriteString(w, "<div class=\"gallery\">\n")
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		img, ok := node.(*ast.Image)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		href := string(img.Destination)
+		if processed, ok := imgs.Lookup(href); ok {
+			href = processed.URL
+		}
+		io.WriteString(w, "<figure"+figureAttrs(img.Attribute, "gallery-item")+">")
+		io.WriteString(w, `<a href="`+html.EscapeString(href)+`">`+imageTag(img, imgs, false, gallerySizes)+"</a>\n")
+		writeCaption(w, img)
+		io.WriteString(w, "</figure>\n")
+		return ast.SkipChildren
+	})
+	io.WriteString(w, "</div>\n")
+}
+
+// writeCaption writes an image's title as a <figcaption>
+func writeCaption(w io.Writer, img *ast.Image) {
+	if len(img.Title) > 0 {
+		io.WriteString(w, "<figcaption>"+html.EscapeString(string(img.Title))+"</figcaption>\n")
+	}
+}
+
+// figureAttrs returns the id and class attributes of a figure
+func figureAttrs(attr *ast.Attribute, class string) string {
+	var a htmlAttrs
+	if attr != nil && len(attr.ID) > 0 {
+		a.set("id", string(attr.ID))
+	}
+	if classes := classList(attr, class); len(classes) > 0 {
+		a.set("class", strings.Join(classes, " "))
+	}
+	return a.String()
+}
+
+// classList returns class, if not empty, followed by the classes of an
+// attribute block. Alignment classes such as .center become align-center.
+func classList(attr *ast.Attribute, class string) []string {
+	var classes []string
+	if class != "" {
+		classes = append(classes, class)
+	}
+	if attr != nil {
+		for _, c := range attr.Classes {
+			if alignments[string(c)] {
+				classes = append(classes, "align-"+string(c))
+			} else {
+				classes = append(classes, string(c))
+			}
+		}
+	}
+	return classes
+}
+
+// imageTag returns the <img> tag for a Markdown image. Images processed by
+// imgs get srcset, sizes and dimensions, and a width attribute scales them
+// while keeping their aspect ratio. Inline images also get their title and
+// the ID and classes of their attribute block. sizes overrides the
+// processor's sizes attribute when not empty.
+func imageTag(img *ast.Image, imgs *images.Processor, inline bool, sizes string) string {
+	src := string(img.Destination)
+	var a htmlAttrs
+	a.set("src", src)
+	a.set("alt", nodeText(img))
+	if inline && len(img.Title) > 0 {
+		a.set("title", string(img.Title))
+	}
+
+	var attrs map[string][]byte
+	if img.Attribute != nil {
+		attrs = img.Attribute.Attrs
+		if inline && len(img.Attribute.ID) > 0 {
+			a.set("id", string(img.Attribute.ID))
+		}
+		if classes := classList(img.Attribute, ""); inline && len(classes) > 0 {
+			a.set("class", strings.Join(classes, " "))
+		}
+	}
+
+	if processed, ok := imgs.Lookup(src); ok {
+		a.set("src", processed.URL)
+		width, height := processed.Width, processed.Height
+		if v, err := strconv.Atoi(string(attrs["width"])); err == nil && v > 0 && width > 0 {
+			height = height * v / width
+			width = v
+			if sizes == "" {
+				sizes = "(max-width: " + strconv.Itoa(v) + "px) 100vw, " + strconv.Itoa(v) + "px"
+			}
+		}
+		if len(processed.Variants) > 0 {
+			if sizes == "" {
+				sizes = imgs.Sizes()
+			}
+			a.set("srcset", processed.SrcSet())
+			a.set("sizes", sizes)
+		}
+		a.set("width", strconv.Itoa(width))
+		a.set("height", strconv.Itoa(height))
+	}
+	a.set("loading", "lazy")
+
+	// Explicit attributes override the generated ones, except src
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		if k != "src" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		a.set(k, string(attrs[k]))
+	}
+	return "<img" + a.String() + " />"
+}
+
+// htmlAttrs is an ordered list of HTML attributes
+type htmlAttrs [][2]string
+
+// set adds an attribute or replaces its value
+func (a *htmlAttrs) set(name, value string) {
+	for i := range *a {
+		if (*a)[i][0] == name {
+			(*a)[i][1] = value
+			return
+		}
+	}
+	*a = append(*a, [2]string{name, value})
+}
+
+// String returns the attributes escaped, each preceded by a space
+func (a htmlAttrs) String() string {
+	var b strings.Builder
+	for _, attr := range a {
+		b.WriteString(" " + attr[0] + `="` + html.EscapeString(attr[1]) + `"`)
+	}
+	return b.String()
+}
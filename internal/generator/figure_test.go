@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAttributeBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		id      string
+		classes []string
+		attrs   map[string]string
+		n       int
+	}{
+		{"classes", "{.left .wide} rest", "", []string{"left", "wide"}, map[string]string{}, 13},
+		{"id and values", `{#hero width=400 title="A long title"}`, "hero", nil, map[string]string{"width": "400", "title": "A long title"}, 38},
+		{"upper case key", "{Width=400}", "", nil, map[string]string{"width": "400"}, 11},
+		{"unclosed", "{.left", "", nil, nil, 0},
+		{"empty", "{}", "", nil, nil, 0},
+		{"line break", "{.left\n}", "", nil, nil, 0},
+		{"invalid key", "{on*click=x}", "", nil, nil, 0},
+		{"missing value", "{width}", "", nil, nil, 0},
+		{"not a block", "text {.left}", "", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attr, n := parseAttributeBlock([]byte(tt.data))
+			assert.Equal(t, tt.n, n)
+			if tt.n == 0 {
+				assert.Nil(t, attr)
+				return
+			}
+			if assert.NotNil(t, attr) {
+				assert.Equal(t, tt.id, string(attr.ID))
+				var classes []string
+				for _, c := range attr.Classes {
+					classes = append(classes, string(c))
+				}
+				assert.Equal(t, tt.classes, classes)
+				attrs := make(map[string]string)
+				for k, v := range attr.Attrs {
+					attrs[k] = string(v)
+				}
+				assert.Equal(t, tt.attrs, attrs)
+			}
+		})
+	}
+}
+
+func TestRenderFigures(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			"inline alignment",
+			"![A cat](cat.jpg){.left}",
+			"<figure class=\"align-left\">\n<img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" />\n</figure>\n",
+		},
+		{
+			"block alignment",
+			"{.right}\n![A cat](cat.jpg)",
+			"<figure class=\"align-right\">\n<img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" />\n</figure>\n",
+		},
+		{
+			"prefixed alignment",
+			"{.align-left}\n![A cat](cat.jpg)",
+			"<figure class=\"align-left\">\n<img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" />\n</figure>\n",
+		},
+		{
+			"caption from title",
+			"![A cat](cat.jpg \"Sleeping <3\")",
+			"<figure>\n<img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" />\n<figcaption>Sleeping &lt;3</figcaption>\n</figure>\n",
+		},
+		{
+			"no caption",
+			"![A cat](cat.jpg)",
+			"<p><img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" /></p>\n",
+		},
+		{
+			"image within text",
+			"Text ![A cat](cat.jpg){.left} more",
+			"<p>Text <img src=\"cat.jpg\" alt=\"A cat\" class=\"align-left\" loading=\"lazy\" /> more</p>\n",
+		},
+		{
+			"id and attributes",
+			"![A cat](cat.jpg){width=400 #hero .center}",
+			"<figure id=\"hero\" class=\"align-center\">\n<img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" width=\"400\" />\n</figure>\n",
+		},
+		{
+			"malformed block",
+			"![A cat](cat.jpg){broken",
+			"<p><img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" />{broken</p>\n",
+		},
+		{
+			"invalid attribute",
+			"![A cat](cat.jpg){on*click=x}",
+			"<p><img src=\"cat.jpg\" alt=\"A cat\" loading=\"lazy\" />{on*click=x}</p>\n",
+		},
+		{
+			"gallery",
+			"```gallery\n![One](a.jpg \"First\")\n![Two](b.jpg){.wide}\n```",
+			"<div class=\"gallery\">\n" +
+				"<figure class=\"gallery-item\"><a href=\"a.jpg\"><img src=\"a.jpg\" alt=\"One\" loading=\"lazy\" /></a>\n<figcaption>First</figcaption>\n</figure>\n" +
+				"<figure class=\"gallery-item wide\"><a href=\"b.jpg\"><img src=\"b.jpg\" alt=\"Two\" loading=\"lazy\" /></a>\n</figure>\n" +
+				"</div>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderMarkdown(tt.markdown, nil, config.TOCConfig{}).HTML)
+		})
+	}
+}
+
+func TestDetectFigures(t *testing.T) {
+	cfg := &config.Config{}
+	figure := renderMarkdown("![A cat](cat.jpg \"Sleeping\")", nil, config.TOCConfig{}).HTML
+	assert.True(t, detectAssets(cfg, figure).Figures)
+	plain := renderMarkdown("![A cat](cat.jpg)", nil, config.TOCConfig{}).HTML
+	assert.False(t, detectAssets(cfg, plain).Figures)
+}
@@ -0,0 +1,29 @@
+package generator
+
+import (
+	"html/template"
+
+	"github.com/intothevoid/likho/internal/images"
+	"github.com/intothevoid/likho/internal/theme"
+)
+
+// templateFuncs returns the functions available to all templates
+func templateFuncs(tm *theme.ThemeManager, imgs *images.Processor) template.FuncMap {
+	return template.FuncMap{
+		"urlize": urlize,
+		// tagURL returns the URL of a tag's page
+		"tagURL": tagURL,
+		// terms returns the terms a post lists under a taxonomy's key, and
+		// termURL the URL of a term's page
+		"terms":   postTerms,
+		"termURL": termURL,
+		// plainify strips the markup from HTML such as a post's summary
+		"plainify": func(s template.HTML) string { return htmlText(string(s)) },
+		// asset resolves a theme asset such as "css/main.css" to its
+		// fingerprinted URL and Subresource Integrity hash
+		"asset": tm.Asset,
+		// resize scales an image such as a featured image to a width,
+		// returning its URL, width and height
+		"resize": imgs.Resize,
+	}
+}
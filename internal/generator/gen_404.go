@@ -0,0 +1,33 @@
+package generator
+
+import (
+	"html/template"
+	"path/filepath"
+	"sort"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+)
+
+// recentPostsOn404 is the number of recent posts suggested on the 404 page
+const recentPostsOn404 = 5
+
+func generate404HTML(cfg *config.Config, tmpl *template.Template, posts []post.Post, layout siteLayout) error {
+	recent := make([]post.Post, len(posts))
+	copy(recent, posts)
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].Date.After(recent[j].Date)
+	})
+
+	data := struct {
+		layoutData
+		RecentPosts []post.Post
+	}{
+		layoutData:  newLayoutData(cfg, "Page not found", layout),
+		RecentPosts: recent[:min(len(recent), recentPostsOn404)],
+	}
+	data.Meta.NoIndex = true
+
+	outputPath := filepath.Join(cfg.Content.OutputDir, "404.html")
+	return executeTemplate(tmpl, "404.html", outputPath, data)
+}
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+)
+
+// archive groups posts by year and month of publication
+type archive struct {
+	URL string
+	// Years are sorted from the newest
+	Years []*archiveYear
+}
+
+// archiveYear holds the posts published in a year
+type archiveYear struct {
+	Year int
+	URL  string
+	// Months are sorted from the newest
+	Months []*archiveMonth
+}
+
+// Count returns the number of posts published in the year
+func (y *archiveYear) Count() int {
+	n := 0
+	for _, m := range y.Months {
+		n += m.Count()
+	}
+	return n
+}
+
+// archiveMonth holds the posts published in a month
+type archiveMonth struct {
+	Year  int
+	Month time.Month
+	URL   string
+	// Posts are sorted from the newest
+	Posts []post.Post
+}
+
+// Count returns the number of posts published in the month
+func (m *archiveMonth) Count() int {
+	return len(m.Posts)
+}
+
+// archiveURL fills the :year and :month placeholders of a URL pattern
+func archiveURL(pattern string, year int, month time.Month) string {
+	return strings.NewReplacer(
+		":year", fmt.Sprintf("%04d", year),
+		":month", fmt.Sprintf("%02d", int(month)),
+	).Replace(pattern)
+}
+
+// newArchive groups posts by year and month, or returns nil when archives
+// are disabled
+func newArchive(cfg *config.Config, posts []post.Post) *archive {
+	if !cfg.Archive.Enabled {
+		return nil
+	}
+	a := &archive{URL: cfg.Archive.URL}
+	sorted := chronological(posts)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		p := sorted[i]
+		year, month := p.Date.Year(), p.Date.Month()
+
+		if len(a.Years) == 0 || a.Years[len(a.Years)-1].Year != year {
+			a.Years = append(a.Years, &archiveYear{Year: year, URL: archiveURL(cfg.Archive.YearURL, year, 0)})
+		}
+		y := a.Years[len(a.Years)-1]
+		if len(y.Months) == 0 || y.Months[len(y.Months)-1].Month != month {
+			y.Months = append(y.Months, &archiveMonth{Year: year, Month: month, URL: archiveURL(cfg.Archive.MonthURL, year, month)})
+		}
+		m := y.Months[len(y.Months)-1]
+		m.Posts = append(m.Posts, p)
+	}
+	return a
+}
+
+// urlOutputPath returns the file written for a site-relative URL, an
+// index.html for URLs ending in a slash
+func urlOutputPath(cfg *config.Config, url string) string {
+	rel := strings.TrimPrefix(url, "/")
+	if rel == "" || strings.HasSuffix(rel, "/") {
+		rel += "index.html"
+	}
+	return filepath.Join(cfg.Content.OutputDir, filepath.FromSlash(rel))
+}
+
+// generateArchiveHTML writes the archive index and a page per year and per
+// month, all with the archive.html template
+func generateArchiveHTML(cfg *config.Config, tmpl *template.Template, layout siteLayout) error {
+	a := layout.Archive
+	if a == nil {
+		return nil
+	}
+
+	write := func(title, url string, years []*archiveYear) error {
+		data := struct {
+			layoutData
+			Years []*archiveYear
+		}{
+			layoutData: newLayoutData(cfg, title, layout),
+			Years:      years,
+		}
+		data.Meta = newPageMeta(cfg, title, "", url)
+
+		outputPath := urlOutputPath(cfg, url)
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+		return executeTemplate(tmpl, "archive.html", outputPath, data)
+	}
+
+	if err := write("Archive", a.URL, a.Years); err != nil {
+		return err
+	}
+	for _, y := range a.Years {
+		if err := write(fmt.Sprint(y.Year), y.URL, []*archiveYear{y}); err != nil {
+			return err
+		}
+		for _, m := range y.Months {
+			only := &archiveYear{Year: y.Year, URL: y.URL, Months: []*archiveMonth{m}}
+			title := fmt.Sprintf("%s %d", m.Month, m.Year)
+			if err := write(title, m.URL, []*archiveYear{only}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// atomPath is the site-relative URL of the Atom feed
+const atomPath = "/atom.xml"
+
+// generateAtom writes an Atom feed of posts, naming the authors of every
+// entry with a link to their page. posts are expected newest first.
+func generateAtom(cfg *config.Config, posts []post.Post, authorList []*author) error {
+	atomFile := filepath.Join(cfg.Content.OutputDir, filepath.FromSlash(strings.TrimPrefix(atomPath, "/")))
+	byRef := newAuthorIndex(authorList)
+
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	b.WriteString("<feed xmlns=\"http://www.w3.org/2005/Atom\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(cfg.Site.Title))
+	if cfg.Site.Description != "" {
+		fmt.Fprintf(&b, "<subtitle>%s</subtitle>\n", html.EscapeString(cfg.Site.Description))
+	}
+	fmt.Fprintf(&b, "<link href=\"%s\"/>\n", html.EscapeString(absURL(cfg, "/")))
+	fmt.Fprintf(&b, "<link rel=\"self\" href=\"%s\"/>\n", html.EscapeString(absURL(cfg, atomPath)))
+	fmt.Fprintf(&b, "<id>%s</id>\n", html.EscapeString(absURL(cfg, "/")))
+	updated := time.Now()
+	if len(posts) > 0 {
+		updated = posts[0].Date
+	}
+	fmt.Fprintf(&b, "<updated>%s</updated>\n", updated.Format(time.RFC3339))
+	// The site's author stands in for entries without one of their own
+	if name := authorName(cfg.Author); name != "" {
+		fmt.Fprintf(&b, "<author><name>%s</name></author>\n", html.EscapeString(name))
+	}
+
+	for _, p := range posts {
+		link := html.EscapeString(absURL(cfg, postURL(p)))
+		b.WriteString("<entry>\n")
+		fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(p.Title))
+		fmt.Fprintf(&b, "<link href=\"%s\"/>\n", link)
+		fmt.Fprintf(&b, "<id>%s</id>\n", link)
+		fmt.Fprintf(&b, "<published>%s</published>\n", p.Date.Format(time.RFC3339))
+		fmt.Fprintf(&b, "<updated>%s</updated>\n", p.Date.Format(time.RFC3339))
+		for _, a := range byRef.of(p) {
+			fmt.Fprintf(&b, "<author>\n<name>%s</name>\n<uri>%s</uri>\n</author>\n", html.EscapeString(a.Name), html.EscapeString(absURL(cfg, a.URL)))
+		}
+		for _, t := range p.Tags {
+			fmt.Fprintf(&b, "<category term=\"%s\"/>\n", html.EscapeString(t))
+		}
+		if p.Description != "" {
+			fmt.Fprintf(&b, "<summary>%s</summary>\n", html.EscapeString(p.Description))
+		}
+		fmt.Fprintf(&b, "<content type=\"html\">%s</content>\n", html.EscapeString(feedContent(cfg, p)))
+		b.WriteString("</entry>\n")
+	}
+	b.WriteString("</feed>\n")
+
+	if err := os.WriteFile(atomFile, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing Atom feed: %v", err)
+	}
+	utils.GetLogger().Info("atom feed generated", zap.String("path", atomFile))
+	return nil
+}
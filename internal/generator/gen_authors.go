@@ -0,0 +1,258 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/intothevoid/likho/internal/authors"
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/images"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/theme"
+)
+
+// authorsDir is the output directory, and URL path, of author pages
+const authorsDir = "authors"
+
+// author is an author with the posts they wrote
+type author struct {
+	authors.Author
+	// Slug names the author's page and feed, from their ID in the authors
+	// data file or else from their name
+	Slug    string
+	URL     string
+	FeedURL string
+	// Posts are sorted from the newest
+	Posts []post.Post
+}
+
+// Count returns the number of posts by the author
+func (a *author) Count() int {
+	return len(a.Posts)
+}
+
+// authorRef identifies an author: by ID when they are in the authors data
+// file, and otherwise by name
+type authorRef struct {
+	ID, Name string
+}
+
+// ref returns the reference of an author
+func (a *author) ref() authorRef {
+	if a.ID != "" {
+		return authorRef{ID: a.ID}
+	}
+	return authorRef{Name: a.Name}
+}
+
+// postAuthorRefs returns the references of a post's authors, in the order
+// the post names them. Authors are expected to be normalized.
+func postAuthorRefs(p post.Post) []authorRef {
+	refs := make([]authorRef, len(p.Authors))
+	for i, name := range p.Authors {
+		if i < len(p.AuthorIDs) && p.AuthorIDs[i] != "" {
+			refs[i] = authorRef{ID: p.AuthorIDs[i]}
+		} else {
+			refs[i] = authorRef{Name: name}
+		}
+	}
+	return refs
+}
+
+// authorIndex finds authors by the references posts carry
+type authorIndex map[authorRef]*author
+
+// newAuthorIndex indexes authors by reference
+func newAuthorIndex(list []*author) authorIndex {
+	idx := make(authorIndex, len(list))
+	for _, a := range list {
+		idx[a.ref()] = a
+	}
+	return idx
+}
+
+// of returns the authors of a post, in the order the post names them
+func (idx authorIndex) of(p post.Post) []*author {
+	var list []*author
+	for _, ref := range postAuthorRefs(p) {
+		if a, ok := idx[ref]; ok {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// authorKey returns the identity of an author's name or ID. Names and IDs
+// differing only in case or spacing, such as "Jane Doe" and "jane  doe",
+// refer to the same author.
+func authorKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// loadAuthors reads the authors data file from the content directory
+func loadAuthors(cfg *config.Config) ([]authors.Author, error) {
+	return authors.Load(filepath.Join(cfg.Content.SourceDir, cfg.Content.AuthorsFile))
+}
+
+// normalizeAuthors replaces the authors of every post with their names and
+// sets their IDs. Posts may name an author by ID or name from the authors
+// data file, regardless of case; a name shared by several authors there is
+// the one with the first ID. Other authors keep the spelling they are first
+// given. Posts naming no author are given the site's author.
+func normalizeAuthors(cfg *config.Config, people []authors.Author, posts []post.Post) {
+	refs := make(map[string]authorRef)
+	for _, a := range people {
+		if _, ok := refs[authorKey(a.Name)]; !ok {
+			refs[authorKey(a.Name)] = authorRef{ID: a.ID, Name: a.Name}
+		}
+	}
+	// IDs take precedence over the names of other authors
+	for _, a := range people {
+		refs[authorKey(a.ID)] = authorRef{ID: a.ID, Name: a.Name}
+	}
+
+	for i := range posts {
+		given := posts[i].Authors
+		if len(given) == 0 {
+			if name := authorName(cfg.Author); name != "" {
+				given = []string{name}
+			}
+		}
+
+		var names, ids []string
+		seen := make(map[authorRef]bool)
+		for _, name := range given {
+			key := authorKey(name)
+			if key == "" {
+				continue
+			}
+			ref, ok := refs[key]
+			if !ok {
+				ref = authorRef{Name: strings.Join(strings.Fields(name), " ")}
+				refs[key] = ref
+			}
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			names = append(names, ref.Name)
+			ids = append(ids, ref.ID)
+		}
+		posts[i].Authors = names
+		posts[i].AuthorIDs = ids
+	}
+}
+
+// collectAuthors groups posts by author, sorting authors by name and the
+// posts of each author from the newest. Authors are expected to be
+// normalized.
+func collectAuthors(people []authors.Author, posts []post.Post) []*author {
+	profiles := make(map[string]authors.Author, len(people))
+	for _, a := range people {
+		profiles[a.ID] = a
+	}
+
+	byRef := make(map[authorRef]*author)
+	var list []*author
+	for _, p := range posts {
+		for i, ref := range postAuthorRefs(p) {
+			a, ok := byRef[ref]
+			if !ok {
+				profile, found := profiles[ref.ID]
+				if ref.ID == "" || !found {
+					profile = authors.Author{Name: p.Authors[i]}
+				}
+				a = &author{Author: profile}
+				byRef[ref] = a
+				list = append(list, a)
+			}
+			a.Posts = append(a.Posts, p)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if a, b := strings.ToLower(list[i].Name), strings.ToLower(list[j].Name); a != b {
+			return a < b
+		}
+		return list[i].ID < list[j].ID
+	})
+	// Authors from the data file claim their slugs first, so their URLs do
+	// not depend on the names of other authors
+	used := make(map[string]bool)
+	for _, fromFile := range []bool{true, false} {
+		for _, a := range list {
+			if (a.ID != "") != fromFile {
+				continue
+			}
+			slug := slugify(a.Name)
+			if fromFile {
+				slug = slugify(a.ID)
+			}
+			a.Slug = uniqueID(slug, used)
+			a.URL = "/" + authorsDir + "/" + a.Slug + ".html"
+			a.FeedURL = "/" + authorsDir + "/" + a.Slug + ".xml"
+		}
+	}
+	for _, a := range list {
+		sort.SliceStable(a.Posts, func(i, j int) bool {
+			return a.Posts[i].Date.After(a.Posts[j].Date)
+		})
+	}
+	return list
+}
+
+// generateAuthorPages writes the page and feed of every author and the
+// authors index
+func generateAuthorPages(cfg *config.Config, tm *theme.ThemeManager, imgs *images.Processor, list []*author, layout siteLayout) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(cfg.Content.OutputDir, authorsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create authors directory: %w", err)
+	}
+
+	funcMap := templateFuncs(tm, imgs)
+	tmpl, err := parseTemplates(cfg, funcMap, "author.html")
+	if err != nil {
+		return err
+	}
+	for _, a := range list {
+		data := struct {
+			layoutData
+			Author *author
+			Posts  []post.Post
+		}{
+			layoutData: newLayoutData(cfg, a.Name, layout),
+			Author:     a,
+			Posts:      a.Posts,
+		}
+		data.Meta = newAuthorMeta(cfg, a)
+
+		if err := executeTemplate(tmpl, "author.html", filepath.Join(dir, a.Slug+".html"), data); err != nil {
+			return err
+		}
+		title := fmt.Sprintf("%s - %s", a.Name, cfg.Site.Title)
+		if err := writeFeed(cfg, filepath.Join(dir, a.Slug+".xml"), title, absURL(cfg, a.URL), a.Bio, a.Posts); err != nil {
+			return err
+		}
+	}
+
+	tmplIndex, err := parseTemplates(cfg, funcMap, "authors.html")
+	if err != nil {
+		return err
+	}
+	data := struct {
+		layoutData
+		Authors []*author
+	}{
+		layoutData: newLayoutData(cfg, "Authors", layout),
+		Authors:    list,
+	}
+	data.Meta = newPageMeta(cfg, "Authors", "", "/"+authorsDir+"/")
+	return executeTemplate(tmplIndex, "authors.html", filepath.Join(dir, "index.html"), data)
+}
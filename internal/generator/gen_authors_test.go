@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/authors"
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAuthors(t *testing.T) {
+	cfg := &config.Config{Author: "Site Owner <owner@example.com>"}
+	people := []authors.Author{
+		{ID: "jane", Name: "Jane Doe"},
+		{ID: "bob", Name: "Bob"},
+	}
+	posts := []post.Post{
+		{Title: "One", Authors: []string{"jane"}},
+		{Title: "Two", Authors: []string{"JANE", "jane  doe", "Bob"}},
+		{Title: "Three", Authors: []string{"José  Núñez", "  "}},
+		{Title: "Four", Authors: []string{"josé núñez", "山田太郎"}},
+		{Title: "Five"},
+	}
+
+	normalizeAuthors(cfg, people, posts)
+
+	// IDs and names from the data file resolve to the name, other authors
+	// keep their first spelling, and posts without authors get the site's
+	assert.Equal(t, []string{"Jane Doe"}, posts[0].Authors)
+	assert.Equal(t, []string{"Jane Doe", "Bob"}, posts[1].Authors)
+	assert.Equal(t, []string{"José Núñez"}, posts[2].Authors)
+	assert.Equal(t, []string{"José Núñez", "山田太郎"}, posts[3].Authors)
+	assert.Equal(t, []string{"Site Owner"}, posts[4].Authors)
+	assert.Equal(t, []string{"jane"}, posts[0].AuthorIDs)
+	assert.Equal(t, []string{"jane", "bob"}, posts[1].AuthorIDs)
+	assert.Equal(t, []string{""}, posts[2].AuthorIDs)
+}
+
+func TestAuthorsSharingAName(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	people := []authors.Author{
+		{ID: "akim", Name: "Alex Kim", Bio: "Photographer"},
+		{ID: "alex", Name: "Alex Kim", Bio: "Programmer"},
+	}
+	posts := []post.Post{
+		{Title: "One", Date: day(1), Authors: []string{"alex"}},
+		{Title: "Two", Date: day(2), Authors: []string{"akim", "ALEX"}},
+		{Title: "Three", Date: day(3), Authors: []string{"Alex Kim"}},
+	}
+
+	normalizeAuthors(&config.Config{}, people, posts)
+	assert.Equal(t, []string{"Alex Kim", "Alex Kim"}, posts[1].Authors)
+	assert.Equal(t, []string{"akim", "alex"}, posts[1].AuthorIDs)
+	// The name refers to the author with the first ID
+	assert.Equal(t, []string{"akim"}, posts[2].AuthorIDs)
+
+	list := collectAuthors(people, posts)
+	if !assert.Len(t, list, 2) {
+		return
+	}
+	assert.Equal(t, "akim", list[0].ID)
+	assert.Equal(t, "Photographer", list[0].Bio)
+	assert.Equal(t, "/authors/akim.html", list[0].URL)
+	assert.Equal(t, 2, list[0].Count())
+	assert.Equal(t, "alex", list[1].ID)
+	assert.Equal(t, "Programmer", list[1].Bio)
+	assert.Equal(t, "/authors/alex.html", list[1].URL)
+	assert.Equal(t, 2, list[1].Count())
+
+	idx := newAuthorIndex(list)
+	assert.Equal(t, []*author{list[0], list[1]}, idx.of(posts[1]))
+	assert.Equal(t, []*author{list[1]}, idx.of(posts[0]))
+}
+
+func TestCollectAuthors(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	people := []authors.Author{
+		{ID: "jane", Name: "Jane Doe"},
+		{ID: "bob", Name: "Bob"},
+	}
+	posts := []post.Post{
+		{Title: "One", Date: day(1), Authors: []string{"Jane Doe"}, AuthorIDs: []string{"jane"}},
+		{Title: "Two", Date: day(2), Authors: []string{"Jane Doe", "José Núñez"}, AuthorIDs: []string{"jane", ""}},
+		{Title: "Three", Date: day(3), Authors: []string{"山田太郎", "C++"}, AuthorIDs: []string{"", ""}},
+		{Title: "Four", Date: day(4), Authors: []string{"jane", "C#"}, AuthorIDs: []string{"", ""}},
+	}
+
+	list := collectAuthors(people, posts)
+
+	var names []string
+	urls := make(map[string]string)
+	for _, a := range list {
+		names = append(names, a.Name)
+		if other, ok := urls[a.URL]; ok {
+			t.Errorf("%q and %q share the URL %s", other, a.Name, a.URL)
+		}
+		urls[a.URL] = a.Name
+	}
+	assert.Equal(t, []string{"C#", "C++", "jane", "Jane Doe", "José Núñez", "山田太郎"}, names)
+
+	idx := newAuthorIndex(list)
+	byName := func(name string) *author { return idx[authorRef{Name: name}] }
+	// Authors from the data file are named by their ID, and others by a
+	// slug of their name that never loses them a page of their own
+	jane := idx[authorRef{ID: "jane"}]
+	if assert.NotNil(t, jane) {
+		assert.Equal(t, "jane", jane.ID)
+		assert.Equal(t, "/authors/jane.html", jane.URL)
+		assert.Equal(t, "/authors/jane.xml", jane.FeedURL)
+		assert.Equal(t, 2, jane.Count())
+		assert.Equal(t, "Two", jane.Posts[0].Title)
+	}
+	// An author named like another's ID does not take their page
+	if other := byName("jane"); assert.NotNil(t, other) {
+		assert.Equal(t, "/authors/jane-1.html", other.URL)
+	}
+	if jose := byName("José Núñez"); assert.NotNil(t, jose) {
+		assert.Equal(t, "/authors/josé-núñez.html", jose.URL)
+	}
+	if yamada := byName("山田太郎"); assert.NotNil(t, yamada) {
+		assert.Equal(t, "/authors/山田太郎.html", yamada.URL)
+	}
+	// Authors missing from the data file and without posts are left out
+	assert.Nil(t, idx[authorRef{ID: "bob"}])
+
+	assert.Equal(t, []*author{jane, byName("José Núñez")}, idx.of(posts[1]))
+}
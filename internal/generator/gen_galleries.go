@@ -0,0 +1,273 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/gallery"
+	"github.com/intothevoid/likho/internal/images"
+	"github.com/intothevoid/likho/internal/theme"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// galleriesDir is the output directory, and URL path, of galleries
+const galleriesDir = "galleries"
+
+// galleryView is a gallery as passed to the templates
+type galleryView struct {
+	gallery.Gallery
+	URL     string
+	Content template.HTML
+	Cover   photoView
+	Photos  []photoView
+}
+
+// photoView is a gallery photo as passed to the templates
+type photoView struct {
+	gallery.Photo
+	// URL is the URL of the photo's page
+	URL string
+	// Image is the photo itself with its resized variants
+	Image images.Image
+}
+
+// galleriesURL returns the URL of the galleries listing
+func galleriesURL() string {
+	return "/" + galleriesDir + ".html"
+}
+
+// galleryURL returns the URL of a gallery's page
+func galleryURL(g gallery.Gallery) string {
+	return "/" + galleriesDir + "/" + slugify(g.Name) + ".html"
+}
+
+// photoPages returns the file names, without extension, of the pages of a
+// gallery's photos. A photo's page is named after the slug of its name, or,
+// when photos such as a.jpg and a.png share that slug, of its file name.
+func photoPages(g gallery.Gallery) []string {
+	count := make(map[string]int)
+	for _, p := range g.Photos {
+		count[slugify(p.Name)]++
+	}
+	pages := make([]string, len(g.Photos))
+	for i, p := range g.Photos {
+		pages[i] = slugify(p.Name)
+		if count[pages[i]] > 1 {
+			pages[i] = slugify(p.Name + "-" + strings.TrimPrefix(filepath.Ext(p.File), "."))
+		}
+	}
+	return pages
+}
+
+// checkGalleryURLs returns an error when two galleries share a page, as
+// directories named "Iceland" and "iceland" would
+func checkGalleryURLs(galleries []gallery.Gallery) error {
+	names := make(map[string]string)
+	for _, g := range galleries {
+		url := galleryURL(g)
+		if other, ok := names[url]; ok {
+			return fmt.Errorf("galleries %q and %q have the same URL %s", other, g.Name, url)
+		}
+		names[url] = g.Name
+	}
+	return nil
+}
+
+// loadGalleries reads the galleries with at least one photo from the
+// galleries directory
+func loadGalleries(cfg *config.Config) ([]gallery.Gallery, error) {
+	return gallery.Load(filepath.Join(cfg.Content.SourceDir, cfg.Content.GalleriesDir))
+}
+
+// generateGalleries writes the gallery photos, a page per gallery and per
+// photo, and the galleries listing
+func generateGalleries(cfg *config.Config, tm *theme.ThemeManager, galleries []gallery.Gallery, layout siteLayout) error {
+	if len(galleries) == 0 {
+		return nil
+	}
+	if err := checkGalleryURLs(galleries); err != nil {
+		return err
+	}
+
+	sourceDir := filepath.Join(cfg.Content.SourceDir, cfg.Content.GalleriesDir)
+	imgs, err := processGalleryPhotos(cfg, sourceDir, galleries)
+	if err != nil {
+		return err
+	}
+
+	views := make([]galleryView, len(galleries))
+	for i, g := range galleries {
+		views[i] = newGalleryView(g, imgs)
+	}
+
+	funcMap := templateFuncs(tm, imgs)
+	tmplGallery, err := parseTemplates(cfg, funcMap, "gallery.html")
+	if err != nil {
+		return err
+	}
+	tmplPhoto, err := parseTemplates(cfg, funcMap, "photo.html")
+	if err != nil {
+		return err
+	}
+	for _, g := range views {
+		if err := generateGalleryHTML(cfg, tmplGallery, g, layout); err != nil {
+			return err
+		}
+		for i := range g.Photos {
+			if err := generatePhotoHTML(cfg, tmplPhoto, g, i, layout); err != nil {
+				return err
+			}
+		}
+	}
+
+	tmplList, err := parseTemplates(cfg, funcMap, "galleries.html")
+	if err != nil {
+		return err
+	}
+	data := struct {
+		layoutData
+		Galleries []galleryView
+	}{
+		layoutData: newLayoutData(cfg, "Galleries", layout),
+		Galleries:  views,
+	}
+	data.Assets.Figures = true
+	data.Meta = newPageMeta(cfg, "Galleries", "", galleriesURL())
+
+	outputPath := filepath.Join(cfg.Content.OutputDir, galleriesDir+".html")
+	return executeTemplate(tmplList, "galleries.html", outputPath, data)
+}
+
+// processGalleryPhotos resizes the photos of every gallery into the output
+// directory and returns the processor, or copies them as they are and
+// returns nil when image processing is disabled. Index and sidecar files
+// are not published.
+func processGalleryPhotos(cfg *config.Config, sourceDir string, galleries []gallery.Gallery) (*images.Processor, error) {
+	outputDir := filepath.Join(cfg.Content.OutputDir, galleriesDir)
+
+	if !cfg.Images.Process {
+		for _, g := range galleries {
+			if err := os.MkdirAll(filepath.Join(outputDir, g.Name), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create directory %s: %v", filepath.Join(outputDir, g.Name), err)
+			}
+			for _, p := range g.Photos {
+				data, err := os.ReadFile(filepath.Join(sourceDir, g.Name, p.File))
+				if err != nil {
+					return nil, fmt.Errorf("error reading photo: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(outputDir, g.Name, p.File), data, 0644); err != nil {
+					return nil, fmt.Errorf("error writing photo: %v", err)
+				}
+			}
+		}
+		return nil, nil
+	}
+
+	opts := imageOptions(cfg, sourceDir, outputDir, "/"+galleriesDir+"/")
+	opts.Skip = func(rel string) bool {
+		return strings.Count(rel, "/") != 1 || !gallery.IsPhoto(rel)
+	}
+	imgs := images.NewProcessor(opts)
+	if err := imgs.Process(); err != nil {
+		return nil, err
+	}
+	utils.GetLogger().Info("gallery photos processed", zap.String("path", outputDir))
+	return imgs, nil
+}
+
+func newGalleryView(g gallery.Gallery, imgs *images.Processor) galleryView {
+	content := renderMarkdown(g.Content, nil, config.TOCConfig{}).HTML
+	view := galleryView{
+		Gallery: g,
+		URL:     galleryURL(g),
+		Content: template.HTML(content),
+	}
+	for i, page := range photoPages(g) {
+		photo := newPhotoView(g, g.Photos[i], page, imgs)
+		if photo.File == g.Cover.File {
+			view.Cover = photo
+		}
+		view.Photos = append(view.Photos, photo)
+	}
+	return view
+}
+
+// newPhotoView returns the view of a photo whose page is named page. The
+// photo itself keeps the path of its source file.
+func newPhotoView(g gallery.Gallery, p gallery.Photo, page string, imgs *images.Processor) photoView {
+	src := "/" + galleriesDir + "/" + g.Name + "/" + p.File
+	img, ok := imgs.Lookup(src)
+	if !ok {
+		img = images.Image{Variant: images.Variant{URL: src}}
+	}
+	return photoView{
+		Photo: p,
+		URL:   "/" + galleriesDir + "/" + slugify(g.Name) + "/" + page + ".html",
+		Image: img,
+	}
+}
+
+func generateGalleryHTML(cfg *config.Config, tmpl *template.Template, g galleryView, layout siteLayout) error {
+	data := struct {
+		layoutData
+		Gallery galleryView
+	}{
+		layoutData: newLayoutData(cfg, g.Title, layout),
+		Gallery:    g,
+	}
+	data.Assets = detectAssets(cfg, string(g.Content))
+	data.Assets.Figures = true
+	data.Meta = newPageMeta(cfg, g.Title, g.Description, g.URL)
+	setPhotoMeta(cfg, &data.Meta, g.Cover)
+
+	outputPath := filepath.Join(cfg.Content.OutputDir, filepath.FromSlash(strings.TrimPrefix(g.URL, "/")))
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create galleries directory: %w", err)
+	}
+	return executeTemplate(tmpl, "gallery.html", outputPath, data)
+}
+
+func generatePhotoHTML(cfg *config.Config, tmpl *template.Template, g galleryView, i int, layout siteLayout) error {
+	photo := g.Photos[i]
+	title := photo.Title
+	if title == "" {
+		title = fmt.Sprintf("%s %d/%d", g.Title, i+1, len(g.Photos))
+	}
+
+	data := struct {
+		layoutData
+		Gallery    galleryView
+		Photo      photoView
+		Prev, Next *photoView
+	}{
+		layoutData: newLayoutData(cfg, title, layout),
+		Gallery:    g,
+		Photo:      photo,
+	}
+	if i > 0 {
+		data.Prev = &g.Photos[i-1]
+	}
+	if i < len(g.Photos)-1 {
+		data.Next = &g.Photos[i+1]
+	}
+	data.Assets.Figures = true
+	data.Meta = newPageMeta(cfg, title, photo.Caption, photo.URL)
+	setPhotoMeta(cfg, &data.Meta, photo)
+
+	outputPath := filepath.Join(cfg.Content.OutputDir, filepath.FromSlash(strings.TrimPrefix(photo.URL, "/")))
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create gallery directory: %w", err)
+	}
+	return executeTemplate(tmpl, "photo.html", outputPath, data)
+}
+
+// setPhotoMeta uses a photo as the preview image of a page
+func setPhotoMeta(cfg *config.Config, m *pageMeta, p photoView) {
+	m.Image = absURL(cfg, p.Image.URL)
+	m.ImageWidth, m.ImageHeight = p.Image.Width, p.Image.Height
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/intothevoid/likho/internal/gallery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGalleryURL(t *testing.T) {
+	assert.Equal(t, "/galleries/iceland.html", galleryURL(gallery.Gallery{Name: "iceland"}))
+	assert.Equal(t, "/galleries/summer-in-iceland.html", galleryURL(gallery.Gallery{Name: "Summer in Iceland"}))
+}
+
+func TestCheckGalleryURLs(t *testing.T) {
+	assert.NoError(t, checkGalleryURLs([]gallery.Gallery{{Name: "iceland"}, {Name: "norway"}}))
+	assert.Error(t, checkGalleryURLs([]gallery.Gallery{{Name: "Iceland"}, {Name: "iceland"}}))
+}
+
+func TestPhotoPages(t *testing.T) {
+	g := gallery.Gallery{Photos: []gallery.Photo{
+		{File: "a.jpg", Name: "a"},
+		{File: "a.png", Name: "a"},
+		{File: "Glacier Lagoon.jpg", Name: "Glacier Lagoon"},
+		{File: "b.jpeg", Name: "b"},
+	}}
+	assert.Equal(t, []string{"a-jpg", "a-png", "glacier-lagoon", "b"}, photoPages(g))
+}
+
+func TestNewGalleryView(t *testing.T) {
+	a := gallery.Photo{File: "a.jpg", Name: "a"}
+	b := gallery.Photo{File: "a.png", Name: "a"}
+	g := gallery.Gallery{Name: "My Trip", Title: "My Trip", Cover: b, Photos: []gallery.Photo{a, b}}
+
+	view := newGalleryView(g, nil)
+	assert.Equal(t, "/galleries/my-trip.html", view.URL)
+	if assert.Len(t, view.Photos, 2) {
+		assert.Equal(t, "/galleries/my-trip/a-jpg.html", view.Photos[0].URL)
+		assert.Equal(t, "/galleries/my-trip/a-png.html", view.Photos[1].URL)
+		assert.Equal(t, "/galleries/My Trip/a.png", view.Photos[1].Image.URL)
+	}
+	assert.Equal(t, view.Photos[1].URL, view.Cover.URL)
+}
@@ -1,81 +1,108 @@
 package generator
 
 import (
-	"fmt"
-	"html/template"
-	"path/filepath"
-
 	"github.com/intothevoid/likho/internal/config"
-	"github.com/intothevoid/likho/internal/parser"
+	"github.com/intothevoid/likho/internal/images"
 	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/theme"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
 
-func generateHTML(cfg *config.Config, posts []post.Post, pages []parser.Page) error {
+func generateHTML(cfg *config.Config, tm *theme.ThemeManager, imgs *images.Processor, posts []post.Post, tocs []tableOfContents, authorList []*author, layout siteLayout) error {
 	// Create a FuncMap with custom functions
-	funcMap := template.FuncMap{
-		"urlize": urlize,
-	}
+	funcMap := templateFuncs(tm, imgs)
 
 	// Parse all templates with the custom functions
-	// tmpl, err := template.New("").Funcs(funcMap).ParseGlob(filepath.Join(cfg.Content.TemplatesDir, "*.html"))
-	tmpl, err := template.New("").Funcs(funcMap).ParseFiles(filepath.Join(cfg.Content.TemplatesDir, "base.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "index.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "header.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "footer.html"))
+	tmpl, err := parseTemplates(cfg, funcMap, "index.html")
 	if err != nil {
-		return fmt.Errorf("error parsing templates: %v", err)
+		return err
 	}
 	utils.GetLogger().Debug("templates parsed", zap.Int("numTemplates", len(tmpl.DefinedTemplates())))
 
 	// Generate index page
-	if err := generateIndexHTML(cfg, tmpl, posts, pages); err != nil {
+	if err := generateIndexHTML(cfg, tmpl, posts, layout); err != nil {
 		return err
 	}
 
 	// Generate post pages
-	tmplPost, err := template.New("").Funcs(funcMap).ParseFiles(filepath.Join(cfg.Content.TemplatesDir, "base.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "post.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "header.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "footer.html"))
+	tmplPost, err := parseTemplates(cfg, funcMap, "post.html")
 	if err != nil {
-		return fmt.Errorf("error parsing templates: %v", err)
+		return err
 	}
-	for _, p := range posts {
-		if err := generatePostHTML(cfg, tmplPost, p, pages); err != nil {
+	allSeries := collectSeries(posts)
+	links := newPostLinks(cfg, posts, allSeries, authorList)
+	for i, p := range posts {
+		if err := generatePostHTML(cfg, tmplPost, p, tocs[i], links[i], layout); err != nil {
+			return err
+		}
+	}
+
+	// Generate series pages. A series taxonomy writes its own pages at the
+	// same URLs instead.
+	if !hasTaxonomy(cfg, "series") {
+		tmplSeries, err := parseTemplates(cfg, funcMap, "series.html")
+		if err != nil {
+			return err
+		}
+		if err := generateSeriesHTML(cfg, tmplSeries, allSeries, layout); err != nil {
 			return err
 		}
 	}
 
 	// Generate pages
-	tmpPages, err := template.New("").Funcs(funcMap).ParseFiles(filepath.Join(cfg.Content.TemplatesDir, "base.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "pages.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "header.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "footer.html"))
+	tmpPages, err := parseTemplates(cfg, funcMap, "pages.html")
 	if err != nil {
-		return fmt.Errorf("error parsing templates: %v", err)
+		return err
 	}
 
 	// Generate html for all pages
-	for _, page := range pages {
-		if err := generatePageHTML(cfg, tmpPages, page, pages); err != nil {
+	for _, page := range layout.Pages {
+		if err := generatePageHTML(cfg, tmpPages, page, layout); err != nil {
 			utils.GetLogger().Error("error generating page", zap.String("title", page.Title), zap.Error(err))
 			return err
 		}
 	}
 
 	// Generate all posts page
-	tmplPosts, err := template.New("").Funcs(funcMap).ParseFiles(filepath.Join(cfg.Content.TemplatesDir, "base.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "posts.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "header.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "footer.html"))
+	tmplPosts, err := parseTemplates(cfg, funcMap, "posts.html")
+	if err != nil {
+		return err
+	}
+	if err := generateAllPostsHTML(cfg, tmplPosts, posts, layout); err != nil {
+		return err
+	}
+
+	// Generate date archives
+	if layout.Archive != nil {
+		tmplArchive, err := parseTemplates(cfg, funcMap, "archive.html")
+		if err != nil {
+			return err
+		}
+		if err := generateArchiveHTML(cfg, tmplArchive, layout); err != nil {
+			return err
+		}
+	}
+
+	// Generate 404 page
+	tmpl404, err := parseTemplates(cfg, funcMap, "404.html")
 	if err != nil {
-		return fmt.Errorf("error parsing templates: %v", err)
+		return err
 	}
-	if err := generateAllPostsHTML(cfg, tmplPosts, posts, pages); err != nil {
+	if err := generate404HTML(cfg, tmpl404, posts, layout); err != nil {
 		return err
 	}
 
+	// Generate search index and page
+	if cfg.Features.Search {
+		tmplSearch, err := parseTemplates(cfg, funcMap, "search.html")
+		if err != nil {
+			return err
+		}
+		if err := generateSearch(cfg, tmplSearch, posts, layout); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -4,36 +4,73 @@ import (
 	"html/template"
 	"path/filepath"
 	"sort"
-	"time"
 
 	"github.com/intothevoid/likho/internal/config"
-	"github.com/intothevoid/likho/internal/parser"
 	"github.com/intothevoid/likho/internal/post"
 )
 
-func generateIndexHTML(cfg *config.Config, tmpl *template.Template, posts []post.Post, pages []parser.Page) error {
+func generateIndexHTML(cfg *config.Config, tmpl *template.Template, posts []post.Post, layout siteLayout) error {
 	// Sort posts by date in descending order
-	// to get the latest posts at the top
+	// to get the latest posts at the top. The pages and feeds
+	// generated after the index keep this order; only the index
+	// moves pinned posts to the top.
 	sort.Slice(posts, func(i, j int) bool {
 		return posts[i].Date.After(posts[j].Date)
 	})
+	latest := pinnedFirst(posts)
 
 	data := struct {
-		Posts       []post.Post
-		Pages       []parser.Page
-		SiteTitle   string
-		CurrentYear int
-		PageTitle   string
-		TotalPosts  int
+		layoutData
+		Posts      []post.Post
+		Featured   []post.Post
+		TotalPosts int
 	}{
-		Posts:       posts[:min(len(posts), cfg.Content.PostsPerPage)],
-		Pages:       pages,
-		SiteTitle:   cfg.Site.Title,
-		CurrentYear: time.Now().Year(),
-		PageTitle:   "Latest",
-		TotalPosts:  len(posts),
+		layoutData: newLayoutData(cfg, "Latest", layout),
+		Posts:      latest[:min(len(latest), cfg.Content.PostsPerPage)],
+		Featured:   featuredPosts(posts),
+		TotalPosts: len(posts),
 	}
+	data.Meta = newPageMeta(cfg, cfg.Site.Title, "", "/")
 
 	outputPath := filepath.Join(cfg.Content.OutputDir, "index.html")
 	return executeTemplate(tmpl, "index.html", outputPath, data)
 }
+
+// byWeight orders posts with a weight before those without, by ascending
+// weight, keeping posts of equal weight in their order
+func byWeight(posts []post.Post) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		a, b := posts[i].Weight, posts[j].Weight
+		if a == 0 || b == 0 {
+			return a != 0 && b == 0
+		}
+		return a < b
+	})
+}
+
+// pinnedFirst returns the posts with the pinned posts moved to the top,
+// ordered by weight, leaving posts untouched
+func pinnedFirst(posts []post.Post) []post.Post {
+	var pinned, rest []post.Post
+	for _, p := range posts {
+		if p.Pinned {
+			pinned = append(pinned, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	byWeight(pinned)
+	return append(pinned, rest...)
+}
+
+// featuredPosts returns the featured posts, ordered by weight
+func featuredPosts(posts []post.Post) []post.Post {
+	var featured []post.Post
+	for _, p := range posts {
+		if p.Featured {
+			featured = append(featured, p)
+		}
+	}
+	byWeight(featured)
+	return featured
+}
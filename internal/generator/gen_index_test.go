@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// titles returns the titles of posts in order
+func titles(posts []post.Post) []string {
+	var out []string
+	for _, p := range posts {
+		out = append(out, p.Title)
+	}
+	return out
+}
+
+func TestPinnedFirst(t *testing.T) {
+	posts := []post.Post{
+		{Title: "Newest"},
+		{Title: "Pinned", Pinned: true},
+		{Title: "Pinned heavy", Pinned: true, Weight: 5},
+		{Title: "Middle"},
+		{Title: "Pinned light", Pinned: true, Weight: 1},
+		{Title: "Pinned old", Pinned: true},
+		{Title: "Oldest"},
+	}
+
+	// Weighted pinned posts come first by weight, then the other pinned
+	// posts and the rest in their date order
+	assert.Equal(t, []string{"Pinned light", "Pinned heavy", "Pinned", "Pinned old", "Newest", "Middle", "Oldest"}, titles(pinnedFirst(posts)))
+	assert.Equal(t, "Newest", posts[0].Title, "posts are left untouched")
+}
+
+func TestFeaturedPosts(t *testing.T) {
+	posts := []post.Post{
+		{Title: "A", Featured: true},
+		{Title: "B"},
+		{Title: "C", Featured: true, Weight: 2},
+		{Title: "D", Featured: true, Weight: 2},
+		{Title: "E", Featured: true, Weight: 1},
+	}
+	assert.Equal(t, []string{"E", "C", "D", "A"}, titles(featuredPosts(posts)))
+}
+
+func TestIndexKeepsDateOrder(t *testing.T) {
+	cfg := &config.Config{}
+	utils.InitLogger(cfg)
+	cfg.Content.OutputDir = t.TempDir()
+	cfg.Content.PostsPerPage = 3
+	cfg.Site.BaseURL = "https://example.com"
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	posts := []post.Post{
+		{Title: "Two", Slug: "2024-01-02", Date: day(2)},
+		{Title: "Pinned", Slug: "2024-01-01", Date: day(1), Pinned: true},
+		{Title: "Four", Slug: "2024-01-04", Date: day(4), Featured: true, Weight: 2},
+		{Title: "Three", Slug: "2024-01-03", Date: day(3), Featured: true, Weight: 1},
+		{Title: "Five", Slug: "2024-01-05", Date: day(5)},
+	}
+
+	tmpl := template.Must(template.New("base.html").Parse(`{{ range .Posts }}{{ .Title }},{{ end }}|{{ range .Featured }}{{ .Title }},{{ end }}|{{ .TotalPosts }}`))
+	assert.NoError(t, generateIndexHTML(cfg, tmpl, posts, siteLayout{}))
+
+	index, err := os.ReadFile(filepath.Join(cfg.Content.OutputDir, "index.html"))
+	assert.NoError(t, err)
+	// The index lists the pinned post first, then the newest posts up to a
+	// page, and the featured posts by weight
+	assert.Equal(t, "Pinned,Five,Four,|Three,Four,|5", string(index))
+
+	// The pages and feeds generated after the index keep the date order
+	assert.Equal(t, []string{"Five", "Four", "Three", "Two", "Pinned"}, titles(posts))
+
+	assert.NoError(t, generateRSS(cfg, posts))
+	feed, err := os.ReadFile(filepath.Join(cfg.Content.OutputDir, "rss.xml"))
+	assert.NoError(t, err)
+	var items []string
+	for _, m := range regexp.MustCompile(`<item>\s*<title>([^<]*)</title>`).FindAllStringSubmatch(string(feed), -1) {
+		items = append(items, m[1])
+	}
+	assert.Equal(t, []string{"Five", "Four", "Three", "Two", "Pinned"}, items)
+}
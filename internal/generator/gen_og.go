@@ -0,0 +1,338 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/theme"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Size of the generated Open Graph images
+const (
+	ogImageWidth  = 1200
+	ogImageHeight = 630
+	ogAccentSize  = 12
+)
+
+// ogCacheVersion is part of every image key and must change whenever the
+// image drawn for the same post and settings would change
+const ogCacheVersion = "1"
+
+// ogImageURL returns the site-relative URL of a post's generated preview image
+func ogImageURL(p post.Post) string {
+	return "/og/" + PostID(p) + ".png"
+}
+
+// generateOGImages renders a preview image for every post without a
+// featured image into public/og/, styled by the theme's og_image settings.
+// Images drawn from the same title, date and settings by a previous build
+// are kept.
+func generateOGImages(cfg *config.Config, tm *theme.ThemeManager, posts []post.Post) error {
+	layout := tm.GetOGImage()
+	if err := checkOGLayout(layout); err != nil {
+		return fmt.Errorf("invalid og_image settings: %v", err)
+	}
+
+	bg := layout.Background
+	if bg == "" {
+		bg = cfg.Theme.Custom.PrimaryColor
+	}
+	colors := make([]color.RGBA, 3)
+	for i, hex := range []string{bg, layout.Foreground, layout.Accent} {
+		c, err := parseHexColor(hex)
+		if err != nil {
+			return fmt.Errorf("invalid og_image colour: %v", err)
+		}
+		colors[i] = c
+	}
+
+	titleFont, titleData, err := loadFont(tm.GetPath(), layout.TitleFont, gobold.TTF)
+	if err != nil {
+		return err
+	}
+	textFont, textData, err := loadFont(tm.GetPath(), layout.TextFont, goregular.TTF)
+	if err != nil {
+		return err
+	}
+	textFace, err := opentype.NewFace(textFont, &opentype.FaceOptions{Size: float64(layout.TextSize), DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return fmt.Errorf("error creating og_image text face: %v", err)
+	}
+	defer textFace.Close()
+
+	// Everything but the post that an image depends on
+	style := sha256.New()
+	fmt.Fprintf(style, "%s\x00%+v\x00%v\x00%s\x00", ogCacheVersion, layout, colors, cfg.Site.Title)
+	style.Write(titleData)
+	style.Write(textData)
+	styleKey := style.Sum(nil)
+	keyDir := filepath.Join(cfg.Build.CacheDir, "og")
+	if cfg.Build.CacheDir != "" {
+		if err := os.MkdirAll(keyDir, 0755); err != nil {
+			return fmt.Errorf("failed to create og cache directory: %w", err)
+		}
+	}
+
+	ogDir := filepath.Join(cfg.Content.OutputDir, "og")
+	if err := os.MkdirAll(ogDir, 0755); err != nil {
+		return fmt.Errorf("failed to create og directory: %w", err)
+	}
+
+	r := &ogRenderer{
+		layout:     layout,
+		background: colors[0],
+		foreground: colors[1],
+		accent:     colors[2],
+		titleFont:  titleFont,
+		textFace:   textFace,
+		siteName:   cfg.Site.Title,
+	}
+	rendered := 0
+	wanted := make(map[string]bool)
+	for _, p := range posts {
+		// Posts with a featured image never use the generated one
+		if p.FeaturedImage != "" {
+			continue
+		}
+
+		wanted[PostID(p)+".png"] = true
+		path := filepath.Join(cfg.Content.OutputDir, filepath.FromSlash(ogImageURL(p)))
+		key := ogImageKey(styleKey, p)
+		keyPath := filepath.Join(keyDir, PostID(p)+".key")
+		if cfg.Build.CacheDir != "" && ogImageCurrent(path, keyPath, key) {
+			continue
+		}
+
+		img, err := r.render(p)
+		if err != nil {
+			return err
+		}
+		if err := writePNG(path, img); err != nil {
+			return err
+		}
+		// Write the key after the image so an interrupted build never
+		// leaves a key pointing at a partial file
+		if cfg.Build.CacheDir != "" {
+			if err := os.WriteFile(keyPath, []byte(key), 0644); err != nil {
+				return fmt.Errorf("error writing og cache: %v", err)
+			}
+		}
+		rendered++
+	}
+
+	// Remove the images of posts that were deleted or renamed, or have
+	// since been given a featured image
+	entries, err := os.ReadDir(ogDir)
+	if err != nil {
+		return fmt.Errorf("failed to list og directory: %w", err)
+	}
+	for _, e := range entries {
+		if wanted[e.Name()] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(ogDir, e.Name())); err != nil {
+			return fmt.Errorf("failed to remove stale og image: %w", err)
+		}
+	}
+
+	utils.GetLogger().Info("og images generated", zap.String("path", ogDir), zap.Int("images", rendered))
+	return nil
+}
+
+// checkOGLayout rejects og_image settings that cannot lay out an image
+func checkOGLayout(l theme.ThemeOGImage) error {
+	switch {
+	case l.TitleSize <= 0:
+		return fmt.Errorf("title_size must be positive, got %d", l.TitleSize)
+	case l.TextSize <= 0:
+		return fmt.Errorf("text_size must be positive, got %d", l.TextSize)
+	case l.MaxLines <= 0:
+		return fmt.Errorf("max_lines must be positive, got %d", l.MaxLines)
+	case l.Padding <= 0 || l.Padding >= ogImageHeight/4:
+		return fmt.Errorf("padding must be between 1 and %d, got %d", ogImageHeight/4-1, l.Padding)
+	}
+	return nil
+}
+
+// ogImageKey identifies the image drawn for a post with the given style
+func ogImageKey(style []byte, p post.Post) string {
+	sum := sha256.New()
+	sum.Write(style)
+	fmt.Fprintf(sum, "%s\x00%s", p.Title, p.Date.Format(time.RFC3339))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// ogImageCurrent reports whether the image at path was drawn for key by a
+// previous build
+func ogImageCurrent(path, keyPath, key string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	data, err := os.ReadFile(keyPath)
+	return err == nil && string(data) == key
+}
+
+// ogRenderer draws preview images with a fixed layout
+type ogRenderer struct {
+	layout     theme.ThemeOGImage
+	background color.RGBA
+	foreground color.RGBA
+	accent     color.RGBA
+	titleFont  *opentype.Font
+	textFace   font.Face
+	siteName   string
+}
+
+func (r *ogRenderer) render(p post.Post) (*image.RGBA, error) {
+	img := image.NewRGBA(image.Rect(0, 0, ogImageWidth, ogImageHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(r.background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, ogImageWidth, ogAccentSize), image.NewUniform(r.accent), image.Point{}, draw.Src)
+
+	pad := r.layout.Padding
+	width := ogImageWidth - 2*pad
+	footer := 0
+	if r.layout.ShowSite || r.layout.ShowDate {
+		footer = r.textFace.Metrics().Height.Ceil() * 2
+	}
+
+	// Shrink the title until it fits in the available lines and height
+	var face font.Face
+	var lines []string
+	for size := r.layout.TitleSize; ; size -= 4 {
+		f, err := opentype.NewFace(r.titleFont, &opentype.FaceOptions{Size: float64(size), DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("error creating og_image title face: %v", err)
+		}
+		if face != nil {
+			face.Close()
+		}
+		face, lines = f, wrapText(f, p.Title, width)
+		height := len(lines) * face.Metrics().Height.Ceil()
+		if size <= 4 || size <= r.layout.TitleSize*3/5 || (len(lines) <= r.layout.MaxLines && height <= ogImageHeight-2*pad-footer) {
+			break
+		}
+	}
+	defer face.Close()
+	if len(lines) > r.layout.MaxLines {
+		lines = lines[:r.layout.MaxLines]
+		lines[len(lines)-1] = strings.TrimRight(lines[len(lines)-1], " .,;:") + "…"
+	}
+
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(r.foreground), Face: face}
+	y := pad + ogAccentSize + face.Metrics().Ascent.Ceil()
+	for _, line := range lines {
+		d.Dot = fixed.P(pad, y)
+		d.DrawString(line)
+		y += face.Metrics().Height.Ceil()
+	}
+
+	d.Face = r.textFace
+	baseline := ogImageHeight - pad
+	if r.layout.ShowSite {
+		d.Dot = fixed.P(pad, baseline)
+		d.DrawString(r.siteName)
+	}
+	if r.layout.ShowDate {
+		date := p.Date.Format("January 2, 2006")
+		d.Dot = fixed.P(ogImageWidth-pad-d.MeasureString(date).Ceil(), baseline)
+		d.DrawString(date)
+	}
+	return img, nil
+}
+
+// wrapText breaks text into lines no wider than width. Words wider than a
+// line, such as titles without spaces, are broken between characters.
+func wrapText(face font.Face, text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate).Ceil() <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = ""
+		for _, r := range word {
+			if font.MeasureString(face, line+string(r)).Ceil() > width && line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// loadFont parses a font file relative to the theme directory, or the
+// fallback font when name is empty, and returns it with the file's data
+func loadFont(themePath, name string, fallback []byte) (*opentype.Font, []byte, error) {
+	data := fallback
+	if name != "" {
+		var err error
+		data, err = os.ReadFile(filepath.Join(themePath, name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading og_image font: %v", err)
+		}
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing og_image font %s: %v", name, err)
+	}
+	return f, data, nil
+}
+
+// parseHexColor parses a colour written as #rgb or #rrggbb
+func parseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("%q is not a hex colour", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("%q is not a hex colour", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file %s: %v", path, err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("error encoding %s: %v", path, err)
+	}
+	return file.Close()
+}
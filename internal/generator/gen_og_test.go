@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/theme"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+func TestCheckOGLayout(t *testing.T) {
+	valid := theme.ThemeOGImage{TitleSize: 72, TextSize: 32, Padding: 80, MaxLines: 4}
+	tests := []struct {
+		name   string
+		change func(l *theme.ThemeOGImage)
+		ok     bool
+	}{
+		{"valid", func(l *theme.ThemeOGImage) {}, true},
+		{"zero max lines", func(l *theme.ThemeOGImage) { l.MaxLines = 0 }, false},
+		{"zero title size", func(l *theme.ThemeOGImage) { l.TitleSize = 0 }, false},
+		{"negative text size", func(l *theme.ThemeOGImage) { l.TextSize = -1 }, false},
+		{"zero padding", func(l *theme.ThemeOGImage) { l.Padding = 0 }, false},
+		{"padding filling the image", func(l *theme.ThemeOGImage) { l.Padding = 400 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := valid
+			tt.change(&l)
+			err := checkOGLayout(l)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestOGRenderOneLine(t *testing.T) {
+	titleFont, err := opentype.Parse(gobold.TTF)
+	assert.NoError(t, err)
+	textFont, err := opentype.Parse(goregular.TTF)
+	assert.NoError(t, err)
+	textFace, err := opentype.NewFace(textFont, &opentype.FaceOptions{Size: 32, DPI: 72, Hinting: font.HintingFull})
+	assert.NoError(t, err)
+	defer textFace.Close()
+
+	r := &ogRenderer{
+		layout:    theme.ThemeOGImage{TitleSize: 72, TextSize: 32, Padding: 80, MaxLines: 1, ShowSite: true, ShowDate: true},
+		titleFont: titleFont,
+		textFace:  textFace,
+		siteName:  "Site",
+	}
+	p := post.Post{Title: "A title far too long to fit on a single line of the preview image", Date: time.Now()}
+	img, err := r.render(p)
+	assert.NoError(t, err)
+	assert.Equal(t, ogImageWidth, img.Bounds().Dx())
+}
+
+func TestOGImageCurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "post.png")
+	keyPath := filepath.Join(dir, "post.key")
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := ogImageKey([]byte("style"), post.Post{Title: "One", Date: day})
+
+	assert.False(t, ogImageCurrent(path, keyPath, key))
+
+	assert.NoError(t, os.WriteFile(path, []byte("png"), 0644))
+	assert.NoError(t, os.WriteFile(keyPath, []byte(key), 0644))
+	assert.True(t, ogImageCurrent(path, keyPath, key))
+
+	// A new title, date or style draws a new image
+	assert.False(t, ogImageCurrent(path, keyPath, ogImageKey([]byte("style"), post.Post{Title: "Two", Date: day})))
+	assert.False(t, ogImageCurrent(path, keyPath, ogImageKey([]byte("style"), post.Post{Title: "One", Date: day.AddDate(0, 0, 1)})))
+	assert.False(t, ogImageCurrent(path, keyPath, ogImageKey([]byte("other"), post.Post{Title: "One", Date: day})))
+}
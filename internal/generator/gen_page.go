@@ -6,13 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/intothevoid/likho/internal/config"
 	"github.com/intothevoid/likho/internal/parser"
 )
 
-func generatePageHTML(cfg *config.Config, tmpl *template.Template, page parser.Page, pages []parser.Page) error {
+func generatePageHTML(cfg *config.Config, tmpl *template.Template, page parser.Page, layout siteLayout) error {
 	// Convert relative image paths to absolute paths in markdown
 	content := page.Content
 	content = strings.ReplaceAll(content, "![", "![/images/")
@@ -26,18 +25,14 @@ func generatePageHTML(cfg *config.Config, tmpl *template.Template, page parser.P
 	content = strings.ReplaceAll(content, "](./other/", "](/other/")
 
 	data := struct {
-		Content     template.HTML
-		SiteTitle   string
-		CurrentYear int
-		PageTitle   string
-		Pages       []parser.Page
+		layoutData
+		Content template.HTML
 	}{
-		Content:     template.HTML(content),
-		SiteTitle:   cfg.Site.Title,
-		CurrentYear: time.Now().Year(),
-		PageTitle:   page.Title,
-		Pages:       pages,
+		layoutData: newLayoutData(cfg, page.Title, layout),
+		Content:    template.HTML(content),
 	}
+	data.Assets = detectAssets(cfg, content)
+	data.Meta = newStaticPageMeta(cfg, page)
 
 	// Create pages directory if it doesn't exist
 	pagesDir := filepath.Join(cfg.Content.OutputDir, "pages")
@@ -4,73 +4,43 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
-	"time"
-	"unicode"
 
-	"github.com/gomarkdown/markdown"
-	"github.com/gomarkdown/markdown/html"
-	mdparser "github.com/gomarkdown/markdown/parser"
 	"github.com/intothevoid/likho/internal/config"
-	"github.com/intothevoid/likho/internal/parser"
 	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/related"
 )
 
-func generatePostHTML(cfg *config.Config, tmpl *template.Template, p post.Post, pages []parser.Page) error {
-	// Convert Markdown content to HTML with syntax highlighting classes
-	extensions := mdparser.CommonExtensions | mdparser.Attributes
-	markdownParser := mdparser.NewWithExtensions(extensions)
-
-	htmlFlags := html.CommonFlags | html.HrefTargetBlank
-	opts := html.RendererOptions{
-		Flags: htmlFlags,
-	}
-	renderer := html.NewRenderer(opts)
-
-	html := markdown.ToHTML([]byte(p.Content), markdownParser, renderer)
-
-	// Convert relative image paths to absolute paths in HTML
-	htmlStr := string(html)
-	htmlStr = strings.ReplaceAll(htmlStr, "src=\"images/", "src=\"/images/")
-	htmlStr = strings.ReplaceAll(htmlStr, "src=\"../images/", "src=\"/images/")
-	htmlStr = strings.ReplaceAll(htmlStr, "src=\"./images/", "src=\"/images/")
-
-	// Convert relative links to files in other directory to absolute paths
-	htmlStr = strings.ReplaceAll(htmlStr, "href=\"other/", "href=\"/other/")
-	htmlStr = strings.ReplaceAll(htmlStr, "href=\"../other/", "href=\"/other/")
-	htmlStr = strings.ReplaceAll(htmlStr, "href=\"./other/", "href=\"/other/")
-
+func generatePostHTML(cfg *config.Config, tmpl *template.Template, p post.Post, toc tableOfContents, links postLinks, layout siteLayout) error {
 	data := struct {
-		Post        post.Post
-		Content     template.HTML
-		SiteTitle   string
-		CurrentYear int
-		PageTitle   string
-		Pages       []parser.Page
+		layoutData
+		Post            post.Post
+		Content         template.HTML
+		TableOfContents tableOfContents
+		Prev, Next      *post.Post
+		Related         []post.Post
+		Series          *seriesPart
+		Authors         []*author
+		Comments        *commentsData
 	}{
-		Post:        p,
-		Content:     template.HTML(htmlStr),
-		SiteTitle:   cfg.Site.Title,
-		CurrentYear: time.Now().Year(),
-		PageTitle:   p.Title,
-		Pages:       pages,
+		layoutData:      newLayoutData(cfg, p.Title, layout),
+		Post:            p,
+		Content:         p.HTML,
+		TableOfContents: toc,
+		Prev:            links.Prev,
+		Next:            links.Next,
+		Related:         links.Related,
+		Series:          links.Series,
+		Authors:         links.Authors,
+		Comments:        newCommentsData(cfg, p),
 	}
+	data.Assets = detectAssets(cfg, string(p.HTML))
+	data.Meta = newPostMeta(cfg, p, links.Authors)
 
-	// Create the file name using both the title and the slug
-	fileName := fmt.Sprintf("%s-%s.html", p.Title, p.Slug)
-
-	// Replace spaces with hyphens and convert to lowercase
-	fileName = strings.ToLower(strings.ReplaceAll(fileName, " ", "-"))
-
-	// Replace all non-alphanumeric characters with an empty string
-	// Allow hyphens, underscores, and periods
-	fileName = strings.Map(func(r rune) rune {
-		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '-' || r == '_' || r == '.' {
-			return r
-		}
-		return -1
-	}, fileName)
+	// The file name combines the title and the slug, matching the links
+	// built by the templates
+	fileName := path.Base(postURL(p))
 
 	// Create posts directory if it doesn't exist
 	postsDir := filepath.Join(cfg.Content.OutputDir, "posts")
@@ -82,3 +52,22 @@ func generatePostHTML(cfg *config.Config, tmpl *template.Template, p post.Post,
 
 	return executeTemplate(tmpl, "post.html", outputPath, data)
 }
+
+// relatedPosts returns the posts related to each post
+func relatedPosts(cfg *config.Config, posts []post.Post) [][]post.Post {
+	found := related.Find(posts, related.Options{
+		Count: cfg.Related.Count,
+		Weights: related.Weights{
+			Tags:    cfg.Related.Weights.Tags,
+			Title:   cfg.Related.Weights.Title,
+			Content: cfg.Related.Weights.Content,
+		},
+	})
+	out := make([][]post.Post, len(posts))
+	for i, indexes := range found {
+		for _, j := range indexes {
+			out[i] = append(out[i], posts[j])
+		}
+	}
+	return out
+}
@@ -3,29 +3,22 @@ package generator
 import (
 	"html/template"
 	"path/filepath"
-	"time"
 
 	"github.com/intothevoid/likho/internal/config"
-	"github.com/intothevoid/likho/internal/parser"
 	"github.com/intothevoid/likho/internal/post"
 )
 
-func generateAllPostsHTML(cfg *config.Config, tmpl *template.Template, posts []post.Post, pages []parser.Page) error {
+func generateAllPostsHTML(cfg *config.Config, tmpl *template.Template, posts []post.Post, layout siteLayout) error {
 	data := struct {
-		Posts       []post.Post
-		SiteTitle   string
-		CurrentYear int
-		PageTitle   string
-		Content     template.HTML
-		Pages       []parser.Page
+		layoutData
+		Posts   []post.Post
+		Content template.HTML
 	}{
-		Posts:       posts,
-		SiteTitle:   cfg.Site.Title,
-		CurrentYear: time.Now().Year(),
-		PageTitle:   "Posts",
-		Content:     "", // Leave empty as we're not using it directly
-		Pages:       pages,
+		layoutData: newLayoutData(cfg, "Posts", layout),
+		Posts:      posts,
+		Content:    "", // Leave empty as we're not using it directly
 	}
+	data.Meta = newPageMeta(cfg, "Posts", "", "/posts.html")
 
 	outputPath := filepath.Join(cfg.Content.OutputDir, "posts.html")
 	return executeTemplate(tmpl, "posts.html", outputPath, data)
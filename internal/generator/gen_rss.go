@@ -2,8 +2,11 @@ package generator
 
 import (
 	"fmt"
+	"html"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/intothevoid/likho/internal/config"
@@ -12,9 +15,27 @@ import (
 	"go.uber.org/zap"
 )
 
+// rssNamespace is the XML namespace of the elements likho adds to feed
+// items for their word count and reading time
+const rssNamespace = "https://github.com/intothevoid/likho"
+
+// rootRelativeURL matches the root-relative URLs of links and images in
+// rendered HTML
+var rootRelativeURL = regexp.MustCompile(`(href|src)="/([^/"][^"]*)?"`)
+
+// srcsetAttr matches the srcset of a responsive image, a list of URLs each
+// followed by its width
+var srcsetAttr = regexp.MustCompile(`srcset="([^"]*)"`)
+
 func generateRSS(cfg *config.Config, posts []post.Post) error {
-	logger := utils.GetLogger()
 	rssPath := filepath.Join(cfg.Content.OutputDir, "rss.xml")
+	return writeFeed(cfg, rssPath, cfg.Site.Title, cfg.Site.BaseURL, cfg.Site.Description, posts)
+}
+
+// writeFeed writes an RSS feed of posts to rssPath. The channel's link is
+// an absolute URL.
+func writeFeed(cfg *config.Config, rssPath, title, link, description string, posts []post.Post) error {
+	logger := utils.GetLogger()
 	file, err := os.Create(rssPath)
 	if err != nil {
 		return fmt.Errorf("error creating RSS file: %v", err)
@@ -26,21 +47,21 @@ func generateRSS(cfg *config.Config, posts []post.Post) error {
 		return fmt.Errorf("error writing RSS header: %v", err)
 	}
 
-	_, err = file.WriteString("<rss version=\"2.0\">\n<channel>\n")
+	_, err = fmt.Fprintf(file, "<rss version=\"2.0\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\" xmlns:likho=\"%s\">\n<channel>\n", rssNamespace)
 	if err != nil {
 		return fmt.Errorf("error writing RSS channel opening tags: %v", err)
 	}
 
 	// Write channel information
-	_, err = fmt.Fprintf(file, "<title>%s</title>\n", cfg.Site.Title)
+	_, err = fmt.Fprintf(file, "<title>%s</title>\n", html.EscapeString(title))
 	if err != nil {
 		return fmt.Errorf("error writing RSS title: %v", err)
 	}
-	_, err = fmt.Fprintf(file, "<link>%s</link>\n", cfg.Site.BaseURL)
+	_, err = fmt.Fprintf(file, "<link>%s</link>\n", link)
 	if err != nil {
 		return fmt.Errorf("error writing RSS link: %v", err)
 	}
-	_, err = fmt.Fprintf(file, "<description>%s</description>\n", cfg.Site.Description)
+	_, err = fmt.Fprintf(file, "<description>%s</description>\n", html.EscapeString(description))
 	if err != nil {
 		return fmt.Errorf("error writing RSS description: %v", err)
 	}
@@ -52,13 +73,12 @@ func generateRSS(cfg *config.Config, posts []post.Post) error {
 			return fmt.Errorf("error writing item opening tag: %v", err)
 		}
 
-		_, err = fmt.Fprintf(file, "<title>%s</title>\n", post.Title)
+		_, err = fmt.Fprintf(file, "<title>%s</title>\n", html.EscapeString(post.Title))
 		if err != nil {
 			return fmt.Errorf("error writing item title: %v", err)
 		}
 
-		link := fmt.Sprintf("%s/%s-%s.html", cfg.Site.BaseURL, urlize(post.Title), post.Slug)
-		_, err = fmt.Fprintf(file, "<link>%s</link>\n", link)
+		_, err = fmt.Fprintf(file, "<link>%s</link>\n", absURL(cfg, postURL(post)))
 		if err != nil {
 			return fmt.Errorf("error writing item link: %v", err)
 		}
@@ -68,7 +88,19 @@ func generateRSS(cfg *config.Config, posts []post.Post) error {
 			return fmt.Errorf("error writing item pubDate: %v", err)
 		}
 
-		_, err = fmt.Fprintf(file, "<description><![CDATA[%s]]></description>\n", post.Content)
+		for _, name := range post.Authors {
+			_, err = fmt.Fprintf(file, "<dc:creator>%s</dc:creator>\n", html.EscapeString(name))
+			if err != nil {
+				return fmt.Errorf("error writing item creator: %v", err)
+			}
+		}
+
+		_, err = fmt.Fprintf(file, "<likho:wordCount>%d</likho:wordCount>\n<likho:readingTime>%d</likho:readingTime>\n", post.WordCount, post.ReadingTime)
+		if err != nil {
+			return fmt.Errorf("error writing item reading time: %v", err)
+		}
+
+		_, err = fmt.Fprintf(file, "<description><![CDATA[%s]]></description>\n", rssContent(cfg, post))
 		if err != nil {
 			return fmt.Errorf("error writing item description: %v", err)
 		}
@@ -87,3 +119,38 @@ func generateRSS(cfg *config.Config, posts []post.Post) error {
 	logger.Info("rss feed generated", zap.String("path", rssPath))
 	return nil
 }
+
+// rssContent returns the HTML of a post for its feed item as the content of
+// a CDATA section
+func rssContent(cfg *config.Config, p post.Post) string {
+	// A CDATA section cannot contain its own end marker
+	return strings.ReplaceAll(feedContent(cfg, p), "]]>", "]]]]><![CDATA[>")
+}
+
+// feedContent returns the HTML of a post for feeds: the whole post, or its
+// summary when rss.content is "summary". Links are made absolute so that
+// they work in feed readers.
+func feedContent(cfg *config.Config, p post.Post) string {
+	var content string
+	if cfg.RSS.Content == "summary" {
+		summary, _ := summarize(p, cfg.Content.SummaryWords)
+		content = string(summary)
+	} else {
+		content = postHTML(p)
+	}
+	content = rootRelativeURL.ReplaceAllStringFunc(content, func(attr string) string {
+		m := rootRelativeURL.FindStringSubmatch(attr)
+		return m[1] + `="` + absURL(cfg, m[2]) + `"`
+	})
+	return srcsetAttr.ReplaceAllStringFunc(content, func(attr string) string {
+		candidates := strings.Split(srcsetAttr.FindStringSubmatch(attr)[1], ",")
+		for i, c := range candidates {
+			c = strings.TrimSpace(c)
+			if strings.HasPrefix(c, "/") && !strings.HasPrefix(c, "//") {
+				c = absURL(cfg, c)
+			}
+			candidates[i] = c
+		}
+		return `srcset="` + strings.Join(candidates, ", ") + `"`
+	})
+}
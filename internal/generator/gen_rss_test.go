@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedContent(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Site.BaseURL = "https://example.com/"
+	p := post.Post{
+		Content: "Markdown",
+		HTML:    `<p><a href="/posts/a.html">A</a> B <img src="/images/a.jpg" srcset="/images/_resized/a-480w.jpg 480w, https://cdn.example.com/a.jpg 960w"></p>`,
+	}
+
+	assert.Equal(t, `<p><a href="https://example.com/posts/a.html">A</a> B <img src="https://example.com/images/a.jpg" srcset="https://example.com/images/_resized/a-480w.jpg 480w, https://cdn.example.com/a.jpg 960w"></p>`, feedContent(cfg, p))
+
+	cfg.RSS.Content = "summary"
+	cfg.Content.SummaryWords = 1
+	assert.Equal(t, `<p><a href="https://example.com/posts/a.html">A</a>…</p>`, feedContent(cfg, p))
+}
@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"html/template"
+	"path/filepath"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/search"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// SearchDocuments converts posts to documents for the search index
+func SearchDocuments(posts []post.Post) []search.Document {
+	docs := make([]search.Document, len(posts))
+	for i, p := range posts {
+		docs[i] = search.Document{
+			Title:       p.Title,
+			Description: p.Description,
+			URL:         postURL(p),
+			Tags:        p.Tags,
+			Date:        p.Date,
+			Body:        postText(p),
+			WordCount:   p.WordCount,
+			ReadingTime: p.ReadingTime,
+		}
+	}
+	return docs
+}
+
+// generateSearch writes the search index and the search page
+func generateSearch(cfg *config.Config, tmpl *template.Template, posts []post.Post, layout siteLayout) error {
+	index := search.NewIndex(SearchDocuments(posts))
+	searchDir := filepath.Join(cfg.Content.OutputDir, "search")
+	if err := index.WriteJSON(searchDir); err != nil {
+		return err
+	}
+	utils.GetLogger().Info("search index generated", zap.String("path", searchDir), zap.Int("documents", len(posts)))
+
+	data := struct {
+		layoutData
+	}{
+		layoutData: newLayoutData(cfg, "Search", layout),
+	}
+	data.Meta = newPageMeta(cfg, "Search", "", "/search.html")
+
+	outputPath := filepath.Join(cfg.Content.OutputDir, "search.html")
+	return executeTemplate(tmpl, "search.html", outputPath, data)
+}
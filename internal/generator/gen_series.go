@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+)
+
+// seriesDir is the output directory, and URL path, of series pages
+const seriesDir = "series"
+
+// series is an ordered group of posts sharing a series name
+type series struct {
+	Name string
+	URL  string
+	// Posts are the parts of the series, oldest first
+	Posts []post.Post
+}
+
+// Total returns the number of parts in the series
+func (s *series) Total() int {
+	return len(s.Posts)
+}
+
+// seriesPart places a post within its series
+type seriesPart struct {
+	*series
+	// Part is the post's position in the series, starting at 1
+	Part int
+	// Prev and Next are the neighbouring parts, nil at either end
+	Prev, Next *post.Post
+}
+
+// postLinks are the other posts a post's page links to
+type postLinks struct {
+	// Prev and Next are the posts published before and after the post
+	Prev, Next *post.Post
+	Related    []post.Post
+	Series     *seriesPart
+	Authors    []*author
+}
+
+// seriesURL returns the URL of a series' index page
+func seriesURL(name string) string {
+	return "/" + seriesDir + "/" + slugify(name) + ".html"
+}
+
+// chronological returns the posts sorted from the oldest, leaving posts
+// untouched
+func chronological(posts []post.Post) []post.Post {
+	sorted := append([]post.Post(nil), posts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	return sorted
+}
+
+// collectSeries groups the posts with a series name into series, in the
+// order their first parts were published. Names sharing a key, such as
+// "Go Basics" and "go-basics", are one series, named as in its first part.
+func collectSeries(posts []post.Post) []*series {
+	var all []*series
+	byKey := make(map[string]*series)
+	for _, p := range chronological(posts) {
+		if p.Series == "" {
+			continue
+		}
+		key := nameKey(p.Series)
+		s, ok := byKey[key]
+		if !ok {
+			s = &series{Name: p.Series, URL: seriesURL(p.Series)}
+			byKey[key] = s
+			all = append(all, s)
+		}
+		s.Posts = append(s.Posts, p)
+	}
+	return all
+}
+
+// newPostLinks returns the links of every post, in the order of posts
+func newPostLinks(cfg *config.Config, posts []post.Post, allSeries []*series, authorList []*author) []postLinks {
+	index := make(map[string]int, len(posts))
+	for i, p := range posts {
+		index[PostID(p)] = i
+	}
+
+	links := make([]postLinks, len(posts))
+	sorted := chronological(posts)
+	for i := range sorted {
+		l := &links[index[PostID(sorted[i])]]
+		if i > 0 {
+			l.Prev = &sorted[i-1]
+		}
+		if i < len(sorted)-1 {
+			l.Next = &sorted[i+1]
+		}
+	}
+
+	for _, s := range allSeries {
+		for i, p := range s.Posts {
+			part := &seriesPart{series: s, Part: i + 1}
+			if i > 0 {
+				part.Prev = &s.Posts[i-1]
+			}
+			if i < len(s.Posts)-1 {
+				part.Next = &s.Posts[i+1]
+			}
+			links[index[PostID(p)]].Series = part
+		}
+	}
+
+	byRef := newAuthorIndex(authorList)
+	for i, p := range posts {
+		links[i].Authors = byRef.of(p)
+	}
+
+	for i, r := range relatedPosts(cfg, posts) {
+		links[i].Related = r
+	}
+	return links
+}
+
+// generateSeriesHTML writes the index page of every series
+func generateSeriesHTML(cfg *config.Config, tmpl *template.Template, allSeries []*series, layout siteLayout) error {
+	if len(allSeries) == 0 {
+		return nil
+	}
+	dir := filepath.Join(cfg.Content.OutputDir, seriesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create series directory: %w", err)
+	}
+
+	for _, s := range allSeries {
+		data := struct {
+			layoutData
+			Series *series
+		}{
+			layoutData: newLayoutData(cfg, s.Name, layout),
+			Series:     s,
+		}
+		data.Meta = newPageMeta(cfg, s.Name, fmt.Sprintf("A series of %d posts", s.Total()), s.URL)
+
+		outputPath := filepath.Join(dir, slugify(s.Name)+".html")
+		if err := executeTemplate(tmpl, "series.html", outputPath, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	posts := []post.Post{
+		{Title: "Go 3", Slug: "2024-01-05", Date: day(5), Series: "go basics"},
+		{Title: "Rust 1", Slug: "2024-01-02", Date: day(2), Series: "Rust"},
+		{Title: "Go 1", Slug: "2024-01-01", Date: day(1), Series: "Go Basics"},
+		{Title: "Other", Slug: "2024-01-03", Date: day(3)},
+		{Title: "Go 2", Slug: "2024-01-04", Date: day(4), Series: "go-basics"},
+	}
+
+	all := collectSeries(posts)
+	if !assert.Len(t, all, 2) {
+		return
+	}
+
+	// Series are ordered by their first part and named as in it
+	assert.Equal(t, "Go Basics", all[0].Name)
+	assert.Equal(t, "/series/go-basics.html", all[0].URL)
+	assert.Equal(t, "Rust", all[1].Name)
+
+	var titles []string
+	for _, p := range all[0].Posts {
+		titles = append(titles, p.Title)
+	}
+	assert.Equal(t, []string{"Go 1", "Go 2", "Go 3"}, titles)
+	assert.Equal(t, 3, all[0].Total())
+	assert.Equal(t, 1, all[1].Total())
+}
+
+func TestNewPostLinksSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	posts := []post.Post{
+		{Title: "Go 3", Slug: "2024-01-05", Date: day(5), Series: "go basics"},
+		{Title: "Other", Slug: "2024-01-03", Date: day(3)},
+		{Title: "Go 2", Slug: "2024-01-04", Date: day(4), Series: "Go-Basics"},
+		{Title: "Go 1", Slug: "2024-01-01", Date: day(1), Series: "Go Basics"},
+	}
+
+	links := newPostLinks(&config.Config{}, posts, collectSeries(posts), nil)
+
+	assert.Nil(t, links[1].Series)
+
+	first := links[3].Series
+	if assert.NotNil(t, first) {
+		assert.Equal(t, 1, first.Part)
+		assert.Nil(t, first.Prev)
+		if assert.NotNil(t, first.Next) {
+			assert.Equal(t, "Go 2", first.Next.Title)
+		}
+	}
+
+	middle := links[2].Series
+	if assert.NotNil(t, middle) {
+		assert.Equal(t, 2, middle.Part)
+		assert.Equal(t, 3, middle.Total())
+		if assert.NotNil(t, middle.Prev) && assert.NotNil(t, middle.Next) {
+			assert.Equal(t, "Go 1", middle.Prev.Title)
+			assert.Equal(t, "Go 3", middle.Next.Title)
+		}
+	}
+
+	last := links[0].Series
+	if assert.NotNil(t, last) {
+		assert.Equal(t, 3, last.Part)
+		assert.Nil(t, last.Next)
+		if assert.NotNil(t, last.Prev) {
+			assert.Equal(t, "Go 2", last.Prev.Title)
+		}
+	}
+
+	// Prev and Next across all posts skip nothing, unlike the series links
+	if assert.NotNil(t, links[2].Prev) {
+		assert.Equal(t, "Other", links[2].Prev.Title)
+	}
+}
@@ -7,12 +7,13 @@ import (
 	"time"
 
 	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/gallery"
 	"github.com/intothevoid/likho/internal/post"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
 
-func generateSitemap(cfg *config.Config, posts []post.Post) error {
+func generateSitemap(cfg *config.Config, posts []post.Post, galleries []gallery.Gallery, taxonomies []*taxonomy, authorList []*author, arch *archive) error {
 	logger := utils.GetLogger()
 	sitemapPath := filepath.Join(cfg.Content.OutputDir, "sitemap.xml")
 	file, err := os.Create(sitemapPath)
@@ -39,13 +40,88 @@ func generateSitemap(cfg *config.Config, posts []post.Post) error {
 
 	// Add post URLs
 	for _, post := range posts {
-		postURL := fmt.Sprintf("%s/%s-%s.html", cfg.Site.BaseURL, urlize(post.Title), post.Slug)
-		err = writeURL(file, postURL, post.Date.Format("2006-01-02"))
+		err = writeURL(file, absURL(cfg, postURL(post)), post.Date.Format("2006-01-02"))
 		if err != nil {
 			return err
 		}
 	}
 
+	// Add tag URLs
+	tags := collectTags(posts)
+	if len(tags) > 0 {
+		if err := writeURL(file, absURL(cfg, tagsURL()), time.Now().Format("2006-01-02")); err != nil {
+			return err
+		}
+	}
+	for _, t := range tags {
+		if err := writeURL(file, absURL(cfg, t.URL), sitemapDate(t.Posts[0].Date)); err != nil {
+			return err
+		}
+	}
+
+	// Add author URLs
+	if len(authorList) > 0 {
+		if err := writeURL(file, absURL(cfg, "/"+authorsDir+"/"), time.Now().Format("2006-01-02")); err != nil {
+			return err
+		}
+	}
+	for _, a := range authorList {
+		if err := writeURL(file, absURL(cfg, a.URL), sitemapDate(a.Posts[0].Date)); err != nil {
+			return err
+		}
+	}
+
+	// Add archive URLs
+	if arch != nil && len(arch.Years) > 0 {
+		if err := writeURL(file, absURL(cfg, arch.URL), sitemapDate(arch.Years[0].Months[0].Posts[0].Date)); err != nil {
+			return err
+		}
+		for _, y := range arch.Years {
+			if err := writeURL(file, absURL(cfg, y.URL), sitemapDate(y.Months[0].Posts[0].Date)); err != nil {
+				return err
+			}
+			for _, m := range y.Months {
+				if err := writeURL(file, absURL(cfg, m.URL), sitemapDate(m.Posts[0].Date)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Add taxonomy URLs
+	for _, t := range taxonomies {
+		if err := writeURL(file, absURL(cfg, t.URL), time.Now().Format("2006-01-02")); err != nil {
+			return err
+		}
+		for _, tr := range t.Terms {
+			if err := writeURL(file, absURL(cfg, tr.URL), sitemapDate(tr.Posts[0].Date)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Add series URLs, unless a series taxonomy replaces their pages
+	if !hasTaxonomy(cfg, "series") {
+		for _, s := range collectSeries(posts) {
+			last := s.Posts[len(s.Posts)-1]
+			if err := writeURL(file, absURL(cfg, s.URL), sitemapDate(last.Date)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Add gallery URLs
+	if len(galleries) > 0 {
+		if err := writeURL(file, cfg.Site.BaseURL+galleriesURL(), sitemapDate(galleries[0].Date)); err != nil {
+			return err
+		}
+	}
+	for _, g := range galleries {
+		if err := writeURL(file, cfg.Site.BaseURL+galleryURL(g), sitemapDate(g.Date)); err != nil {
+			return err
+		}
+	}
+
 	_, err = file.WriteString("</urlset>")
 	if err != nil {
 		return fmt.Errorf("error writing urlset closing tag: %v", err)
@@ -54,3 +130,12 @@ func generateSitemap(cfg *config.Config, posts []post.Post) error {
 	logger.Info("sitemap generated", zap.String("path", sitemapPath))
 	return nil
 }
+
+// sitemapDate formats a last modification date, using today for content
+// without a date
+func sitemapDate(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.Format("2006-01-02")
+}
@@ -2,63 +2,225 @@ package generator
 
 import (
 	"fmt"
-	"html/template"
+	"math"
 	"os"
 	"path/filepath"
-	"time"
+	"sort"
+	"strings"
 
 	"github.com/intothevoid/likho/internal/config"
-	"github.com/intothevoid/likho/internal/parser"
+	"github.com/intothevoid/likho/internal/images"
 	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/theme"
 )
 
-func generateTagPages(cfg *config.Config, posts []post.Post, pages []parser.Page) error {
-	// Create a FuncMap with custom functions
-	funcMap := template.FuncMap{
-		"urlize": urlize,
+// maxTagWeight is the weight of the most used tags in the tag cloud, the
+// least used having a weight of 1
+const maxTagWeight = 5
+
+// tag is a tag with the posts using it
+type tag struct {
+	// Name is the tag's display name
+	Name string
+	URL  string
+	// Posts are sorted from the newest
+	Posts []post.Post
+	// Weight ranks the tag's use from 1 to maxTagWeight for tag clouds
+	Weight int
+}
+
+// Count returns the number of posts with the tag
+func (t *tag) Count() int {
+	return len(t.Posts)
+}
+
+// tagsURL returns the URL of the tags index
+func tagsURL() string {
+	return "/tags/"
+}
+
+// tagURL returns the URL of a tag's page
+func tagURL(name string) string {
+	return tagsURL() + slugify(name) + ".html"
+}
+
+// tagKey returns the identity of a tag. Tags with the same key, such as
+// "Go" and "go", are the same tag and share a page, while "C", "C++" and
+// "C#" are different tags.
+func tagKey(name string) string {
+	return nameKey(name)
+}
+
+// normalizeTags replaces the tags of every post with their display names,
+// dropping repeated tags. Aliases under tags.aliases are replaced by the
+// name they map to, which is also how every spelling of that tag is shown.
+// Other tags are shown as spelled by most posts, or by the oldest post on a
+// tie. Aliases are read in sorted order, so that when several spell the same
+// alias or name, the first of them wins on every build.
+func normalizeTags(cfg *config.Config, posts []post.Post) {
+	keys := make([]string, 0, len(cfg.Tags.Aliases))
+	for alias := range cfg.Tags.Aliases {
+		keys = append(keys, alias)
+	}
+	sort.Strings(keys)
+	aliases := make(map[string]string)
+	targets := make(map[string]string)
+	for _, alias := range keys {
+		name := strings.TrimSpace(cfg.Tags.Aliases[alias])
+		if _, ok := targets[tagKey(name)]; !ok {
+			targets[tagKey(name)] = name
+		}
+		if _, ok := aliases[tagKey(alias)]; !ok {
+			aliases[tagKey(alias)] = targets[tagKey(name)]
+		}
+	}
+	canonical := func(name string) string {
+		if alias, ok := aliases[tagKey(name)]; ok {
+			return alias
+		}
+		return strings.Join(strings.Fields(name), " ")
 	}
 
-	tmpl, err := template.New("").Funcs(funcMap).ParseFiles(filepath.Join(cfg.Content.TemplatesDir, "base.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "tags.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "header.html"),
-		filepath.Join(cfg.Content.TemplatesDir, "footer.html"))
-	if err != nil {
-		return fmt.Errorf("error parsing templates: %v", err)
+	// Count the spellings of every tag in the order they first appear
+	counts := make(map[string]int)
+	spellings := make(map[string][]string)
+	for _, p := range chronological(posts) {
+		for _, name := range p.Tags {
+			name = canonical(name)
+			key := tagKey(name)
+			if key == "" {
+				continue
+			}
+			if counts[name] == 0 {
+				spellings[key] = append(spellings[key], name)
+			}
+			counts[name]++
+		}
+	}
+	display := make(map[string]string, len(spellings))
+	for key, names := range spellings {
+		best := names[0]
+		for _, name := range names[1:] {
+			if counts[name] > counts[best] {
+				best = name
+			}
+		}
+		display[key] = best
+	}
+	// The names aliases map to are also the display names of their tags
+	for key, name := range targets {
+		if _, ok := display[key]; ok {
+			display[key] = name
+		}
 	}
 
-	tags := make(map[string][]post.Post)
+	for i := range posts {
+		var tags []string
+		seen := make(map[string]bool)
+		for _, name := range posts[i].Tags {
+			key := tagKey(canonical(name))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			tags = append(tags, display[key])
+		}
+		posts[i].Tags = tags
+	}
+}
+
+// collectTags groups posts by tag, sorting tags by name and the posts of
+// each tag from the newest. Tags are expected to be normalized.
+func collectTags(posts []post.Post) []*tag {
+	byName := make(map[string]*tag)
+	var tags []*tag
 	for _, p := range posts {
-		for _, tag := range p.Tags {
-			tags[tag] = append(tags[tag], p)
+		for _, name := range p.Tags {
+			t, ok := byName[name]
+			if !ok {
+				t = &tag{Name: name, URL: tagURL(name)}
+				byName[name] = t
+				tags = append(tags, t)
+			}
+			t.Posts = append(t.Posts, p)
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := strings.ToLower(tags[i].Name), strings.ToLower(tags[j].Name)
+		if a != b {
+			return a < b
 		}
+		return tags[i].Name < tags[j].Name
+	})
+	minCount, maxCount := math.MaxInt, 0
+	for _, t := range tags {
+		sort.SliceStable(t.Posts, func(i, j int) bool {
+			return t.Posts[i].Date.After(t.Posts[j].Date)
+		})
+		minCount, maxCount = min(minCount, t.Count()), max(maxCount, t.Count())
 	}
+	for _, t := range tags {
+		t.Weight = tagWeight(t.Count(), minCount, maxCount)
+	}
+	return tags
+}
+
+// tagWeight scales a tag's number of posts to a weight from 1 to
+// maxTagWeight on a logarithmic scale, so a few very common tags do not
+// flatten the others
+func tagWeight(count, minCount, maxCount int) int {
+	if maxCount <= minCount {
+		return 1
+	}
+	scale := math.Log(float64(count)/float64(minCount)) / math.Log(float64(maxCount)/float64(minCount))
+	return 1 + int(math.Round(scale*(maxTagWeight-1)))
+}
 
-	for tag, tagPosts := range tags {
+func generateTagPages(cfg *config.Config, tm *theme.ThemeManager, imgs *images.Processor, posts []post.Post, layout siteLayout) error {
+	// Create a FuncMap with custom functions
+	funcMap := templateFuncs(tm, imgs)
+
+	tmpl, err := parseTemplates(cfg, funcMap, "tags.html")
+	if err != nil {
+		return err
+	}
+
+	tagsDir := filepath.Join(cfg.Content.OutputDir, "tags")
+	if err := os.MkdirAll(tagsDir, 0755); err != nil {
+		return err
+	}
+
+	tags := collectTags(posts)
+	for _, t := range tags {
 		data := struct {
-			Posts       []post.Post
-			Pages       []parser.Page
-			SiteTitle   string
-			CurrentYear int
-			PageTitle   string
-			Tag         string
+			layoutData
+			Posts []post.Post
+			Tag   string
 		}{
-			Posts:       tagPosts,
-			Pages:       pages,
-			SiteTitle:   cfg.Site.Title,
-			CurrentYear: time.Now().Year(),
-			PageTitle:   fmt.Sprintf("Posts tagged with %s", tag),
-			Tag:         tag,
+			layoutData: newLayoutData(cfg, fmt.Sprintf("Posts tagged with %s", t.Name), layout),
+			Posts:      t.Posts,
+			Tag:        t.Name,
 		}
+		data.Meta = newPageMeta(cfg, data.PageTitle, "", t.URL)
 
-		// Use urlize function here to ensure consistency
-		outputPath := filepath.Join(cfg.Content.OutputDir, "tags", urlize(tag)+".html")
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-			return err
-		}
+		outputPath := filepath.Join(tagsDir, slugify(t.Name)+".html")
 		if err := executeTemplate(tmpl, "tags.html", outputPath, data); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	tmplIndex, err := parseTemplates(cfg, funcMap, "tag-index.html")
+	if err != nil {
+		return err
+	}
+	data := struct {
+		layoutData
+		Tags []*tag
+	}{
+		layoutData: newLayoutData(cfg, "Tags", layout),
+		Tags:       tags,
+	}
+	data.Meta = newPageMeta(cfg, "Tags", "", tagsURL())
+	return executeTemplate(tmplIndex, "tag-index.html", filepath.Join(tagsDir, "index.html"), data)
 }
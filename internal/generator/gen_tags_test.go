@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	cfg := &config.Config{Tags: config.TagsConfig{Aliases: map[string]string{"golang": "Go", "js": "JavaScript"}}}
+	posts := []post.Post{
+		{Title: "One", Date: day(1), Tags: []string{"go", "Machine  Learning", "C"}},
+		{Title: "Two", Date: day(2), Tags: []string{"golang", "machine-learning", "C++"}},
+		{Title: "Three", Date: day(3), Tags: []string{"GO", "machine_learning", "C#", "c++"}},
+		{Title: "Four", Date: day(4), Tags: []string{"js", "javascript", "日本語", "中文", "  "}},
+		{Title: "Five", Date: day(5), Tags: []string{"Golang", "日本語", "中文"}},
+	}
+
+	normalizeTags(cfg, posts)
+
+	// Every spelling of an aliased tag shows the alias' target, and other
+	// tags the spelling used by most posts or by the oldest on a tie
+	assert.Equal(t, []string{"Go", "Machine Learning", "C"}, posts[0].Tags)
+	assert.Equal(t, []string{"Go", "Machine Learning", "C++"}, posts[1].Tags)
+	assert.Equal(t, []string{"Go", "Machine Learning", "C#", "C++"}, posts[2].Tags)
+	assert.Equal(t, []string{"JavaScript", "日本語", "中文"}, posts[3].Tags)
+	assert.Equal(t, []string{"Go", "日本語", "中文"}, posts[4].Tags)
+}
+
+func TestNormalizeTagsConflictingAliases(t *testing.T) {
+	// Aliases spelling the same tag differently resolve the same way on
+	// every run, whatever the map's iteration order
+	for i := 0; i < 20; i++ {
+		cfg := &config.Config{Tags: config.TagsConfig{Aliases: map[string]string{
+			"golang":     "Go",
+			"go-lang":    "GO",
+			"Golang ":    "Rust",
+			"k8s":        "Kubernetes",
+			"kube":       "kubernetes",
+			"ecmascript": "JavaScript",
+		}}}
+		posts := []post.Post{
+			{Title: "One", Tags: []string{"golang", "go", "kube", "kubernetes"}},
+			{Title: "Two", Tags: []string{"Go-Lang", "k8s", "ecmascript"}},
+		}
+
+		normalizeTags(cfg, posts)
+
+		// "Golang " sorts before "golang", and "go-lang", naming "GO", before
+		// the "golang" naming "Go"
+		assert.Equal(t, []string{"Rust", "GO", "Kubernetes"}, posts[0].Tags)
+		assert.Equal(t, []string{"GO", "Kubernetes", "JavaScript"}, posts[1].Tags)
+	}
+}
+
+func TestCollectTags(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	posts := []post.Post{
+		{Title: "One", Date: day(1), Tags: []string{"Go", "C"}},
+		{Title: "Two", Date: day(2), Tags: []string{"Go", "C++"}},
+		{Title: "Three", Date: day(3), Tags: []string{"Go", "C#", "日本語"}},
+		{Title: "Four", Date: day(4), Tags: []string{"Go", "中文"}},
+	}
+
+	tags := collectTags(posts)
+
+	var names []string
+	urls := make(map[string]string)
+	for _, tg := range tags {
+		names = append(names, tg.Name)
+		if other, ok := urls[tg.URL]; ok {
+			t.Errorf("%q and %q share the URL %s", other, tg.Name, tg.URL)
+		}
+		urls[tg.URL] = tg.Name
+	}
+	assert.Equal(t, []string{"C", "C#", "C++", "Go", "中文", "日本語"}, names)
+
+	goTag := tags[3]
+	assert.Equal(t, "/tags/go.html", goTag.URL)
+	assert.Equal(t, 4, goTag.Count())
+	assert.Equal(t, "Four", goTag.Posts[0].Title)
+	assert.Equal(t, "One", goTag.Posts[3].Title)
+	assert.Equal(t, maxTagWeight, goTag.Weight)
+
+	assert.Equal(t, "/tags/c.html", tags[0].URL)
+	assert.Equal(t, 1, tags[0].Weight)
+	assert.Equal(t, "/tags/中文.html", tags[4].URL)
+	assert.Equal(t, "/tags/日本語.html", tags[5].URL)
+}
+
+func TestTagWeight(t *testing.T) {
+	assert.Equal(t, 1, tagWeight(3, 3, 3))
+	assert.Equal(t, 1, tagWeight(1, 1, 100))
+	assert.Equal(t, 3, tagWeight(10, 1, 100))
+	assert.Equal(t, maxTagWeight, tagWeight(100, 1, 100))
+}
@@ -0,0 +1,256 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/images"
+	"github.com/intothevoid/likho/internal/parser"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/theme"
+)
+
+// builtinTaxonomies are front matter keys with pages of their own that
+// cannot be configured as taxonomies
+var builtinTaxonomies = map[string]bool{
+	"tags":    true,
+	"author":  true,
+	"authors": true,
+}
+
+// reservedDirs are output directories written by the generator, which a
+// taxonomy's pages would overwrite. A series taxonomy is allowed as it
+// replaces the built-in series pages.
+var reservedDirs = map[string]bool{
+	"posts":      true,
+	"pages":      true,
+	"search":     true,
+	"images":     true,
+	"og":         true,
+	"vendor":     true,
+	"archive":    true,
+	galleriesDir: true,
+	authorsDir:   true,
+}
+
+// checkTaxonomyName returns an error when a taxonomy's name is a front
+// matter key or output directory of the generator's own
+func checkTaxonomyName(cfg *config.Config, name string) error {
+	switch {
+	case name == "" || strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("invalid taxonomy name %q", name)
+	case builtinTaxonomies[name]:
+		return fmt.Errorf("invalid taxonomy %q: likho writes its own %s pages", name, name)
+	case reservedDirs[name] || name == cfg.Content.OtherDir:
+		return fmt.Errorf("invalid taxonomy %q: %s is a reserved output directory", name, name)
+	}
+	return nil
+}
+
+// taxonomy is a configured taxonomy with the terms used by posts
+type taxonomy struct {
+	// Name is the front matter key, also used in URLs
+	Name  string
+	Title string
+	URL   string
+	// Terms are sorted by name
+	Terms  []*term
+	config config.TaxonomyConfig
+}
+
+// term is a value of a taxonomy shared by posts
+type term struct {
+	// Name is the term as first written in front matter
+	Name    string
+	Slug    string
+	URL     string
+	FeedURL string
+	// Title, Description and Content come from the term's Markdown file,
+	// content/<taxonomy>/<slug>.md. Title defaults to Name.
+	Title       string
+	Description string
+	Content     template.HTML
+	// Posts are sorted from the newest
+	Posts []post.Post
+}
+
+// Count returns the number of posts with the term
+func (t *term) Count() int {
+	return len(t.Posts)
+}
+
+// termURL returns the URL of a term's page
+func termURL(taxonomy, name string) string {
+	return "/" + taxonomy + "/" + slugify(name) + ".html"
+}
+
+// postTerms returns the terms a post lists under a front matter key, which
+// may hold a single term or a list
+func postTerms(p post.Post, key string) []string {
+	var values []interface{}
+	switch v := p.Params[key].(type) {
+	case []interface{}:
+		values = v
+	case nil:
+		return nil
+	default:
+		values = []interface{}{v}
+	}
+
+	var terms []string
+	for _, v := range values {
+		if s := strings.TrimSpace(fmt.Sprint(v)); s != "" {
+			terms = append(terms, s)
+		}
+	}
+	return terms
+}
+
+// hasTaxonomy reports whether a taxonomy is configured
+func hasTaxonomy(cfg *config.Config, name string) bool {
+	_, ok := cfg.Taxonomies[name]
+	return ok && !builtinTaxonomies[name]
+}
+
+// collectTaxonomies groups the posts by the terms of every configured
+// taxonomy, in order of name. Terms with the same nameKey, such as "Go" and
+// "go", are the same term.
+func collectTaxonomies(cfg *config.Config, posts []post.Post) ([]*taxonomy, error) {
+	var names []string
+	for name := range cfg.Taxonomies {
+		if err := checkTaxonomyName(cfg, name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var taxonomies []*taxonomy
+	for _, name := range names {
+		tc := cfg.Taxonomies[name]
+		if tc.Template == "" {
+			tc.Template = "term.html"
+		}
+		if tc.IndexTemplate == "" {
+			tc.IndexTemplate = "terms.html"
+		}
+		t := &taxonomy{Name: name, Title: tc.Title, URL: "/" + name + "/", config: tc}
+		if t.Title == "" {
+			r, size := utf8.DecodeRuneInString(name)
+			t.Title = string(unicode.ToTitle(r)) + name[size:]
+		}
+
+		descriptions, err := parser.ParsePages(filepath.Join(cfg.Content.SourceDir, name))
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[string]*term)
+		for _, p := range chronological(posts) {
+			for _, value := range postTerms(p, t.Name) {
+				key := nameKey(value)
+				tr, ok := byKey[key]
+				if !ok {
+					tr = &term{Name: value, Slug: slugify(value), URL: termURL(t.Name, value), Title: value}
+					if tc.Feed {
+						tr.FeedURL = strings.TrimSuffix(tr.URL, ".html") + ".xml"
+					}
+					// Description files are named by the term's slug, or
+					// by the term itself when that is a valid file name
+					for _, d := range descriptions {
+						if d.Slug == tr.Slug || nameKey(d.Slug) == key {
+							tr.Description = d.Description
+							tr.Content = template.HTML(d.Content)
+							if d.Title != "" {
+								tr.Title = d.Title
+							}
+						}
+					}
+					byKey[key] = tr
+					t.Terms = append(t.Terms, tr)
+				}
+				tr.Posts = append(tr.Posts, p)
+			}
+		}
+		for _, tr := range t.Terms {
+			slices.Reverse(tr.Posts)
+		}
+		sort.Slice(t.Terms, func(i, j int) bool {
+			return strings.ToLower(t.Terms[i].Name) < strings.ToLower(t.Terms[j].Name)
+		})
+		taxonomies = append(taxonomies, t)
+	}
+	return taxonomies, nil
+}
+
+// generateTaxonomies writes the index page of every configured taxonomy and
+// the pages and feeds of its terms. It returns the taxonomies for the
+// sitemap.
+func generateTaxonomies(cfg *config.Config, tm *theme.ThemeManager, imgs *images.Processor, posts []post.Post, layout siteLayout) ([]*taxonomy, error) {
+	taxonomies, err := collectTaxonomies(cfg, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	funcMap := templateFuncs(tm, imgs)
+	for _, t := range taxonomies {
+		dir := filepath.Join(cfg.Content.OutputDir, t.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s directory: %w", t.Name, err)
+		}
+
+		tmplTerm, err := parseTemplates(cfg, funcMap, t.config.Template)
+		if err != nil {
+			return nil, err
+		}
+		for _, tr := range t.Terms {
+			data := struct {
+				layoutData
+				Taxonomy *taxonomy
+				Term     *term
+				Posts    []post.Post
+			}{
+				layoutData: newLayoutData(cfg, tr.Title, layout),
+				Taxonomy:   t,
+				Term:       tr,
+				Posts:      tr.Posts,
+			}
+			data.Meta = newPageMeta(cfg, tr.Title, tr.Description, tr.URL)
+			data.Assets = detectAssets(cfg, string(tr.Content))
+
+			if err := executeTemplate(tmplTerm, t.config.Template, filepath.Join(dir, tr.Slug+".html"), data); err != nil {
+				return nil, err
+			}
+			if tr.FeedURL != "" {
+				title := fmt.Sprintf("%s - %s", tr.Title, cfg.Site.Title)
+				if err := writeFeed(cfg, filepath.Join(dir, tr.Slug+".xml"), title, absURL(cfg, tr.URL), tr.Description, tr.Posts); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		tmplIndex, err := parseTemplates(cfg, funcMap, t.config.IndexTemplate)
+		if err != nil {
+			return nil, err
+		}
+		data := struct {
+			layoutData
+			Taxonomy *taxonomy
+		}{
+			layoutData: newLayoutData(cfg, t.Title, layout),
+			Taxonomy:   t,
+		}
+		data.Meta = newPageMeta(cfg, t.Title, "", t.URL)
+		if err := executeTemplate(tmplIndex, t.config.IndexTemplate, filepath.Join(dir, "index.html"), data); err != nil {
+			return nil, err
+		}
+	}
+	return taxonomies, nil
+}
@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectTaxonomies(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "categories"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "categories", "日本語.md"), []byte("---\ntitle: \"Japanese\"\ndescription: \"Posts in Japanese\"\n---\n"), 0644))
+
+	cfg := &config.Config{
+		Content:    config.ContentConfig{SourceDir: dir},
+		Taxonomies: map[string]config.TaxonomyConfig{"categories": {}},
+	}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	categories := func(terms ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"categories": terms}
+	}
+	posts := []post.Post{
+		{Title: "One", Date: day(1), Params: categories("Machine Learning", "C")},
+		{Title: "Two", Date: day(2), Params: categories("machine-learning", "C++")},
+		{Title: "Three", Date: day(3), Params: categories("C#", "日本語")},
+		{Title: "Four", Date: day(4), Params: categories("中文")},
+	}
+
+	taxonomies, err := collectTaxonomies(cfg, posts)
+	assert.NoError(t, err)
+	if !assert.Len(t, taxonomies, 1) {
+		return
+	}
+	tx := taxonomies[0]
+	assert.Equal(t, "Categories", tx.Title)
+
+	byName := make(map[string]*term)
+	urls := make(map[string]bool)
+	for _, tr := range tx.Terms {
+		byName[tr.Name] = tr
+		assert.False(t, urls[tr.URL], "duplicate URL %s", tr.URL)
+		urls[tr.URL] = true
+	}
+	assert.Len(t, tx.Terms, 6)
+
+	if ml := byName["Machine Learning"]; assert.NotNil(t, ml) {
+		assert.Equal(t, "/categories/machine-learning.html", ml.URL)
+		assert.Equal(t, []string{"Two", "One"}, []string{ml.Posts[0].Title, ml.Posts[1].Title})
+	}
+	for _, name := range []string{"C", "C++", "C#"} {
+		if assert.NotNil(t, byName[name], name) {
+			assert.Equal(t, 1, byName[name].Count(), name)
+		}
+	}
+	if ja := byName["日本語"]; assert.NotNil(t, ja) {
+		assert.Equal(t, "/categories/日本語.html", ja.URL)
+		assert.Equal(t, "Japanese", ja.Title)
+		assert.Equal(t, "Posts in Japanese", ja.Description)
+	}
+	if zh := byName["中文"]; assert.NotNil(t, zh) {
+		assert.Equal(t, "/categories/中文.html", zh.URL)
+	}
+}
+
+func TestCollectTaxonomiesReservedNames(t *testing.T) {
+	for _, name := range []string{"tags", "author", "authors", "posts", "pages", "search", "images", "og", "vendor", "galleries", "archive", "other"} {
+		cfg := &config.Config{
+			Content:    config.ContentConfig{SourceDir: t.TempDir(), OtherDir: "other"},
+			Taxonomies: map[string]config.TaxonomyConfig{name: {}},
+		}
+		_, err := collectTaxonomies(cfg, nil)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), `"`+name+`"`)
+		}
+	}
+
+	cfg := &config.Config{
+		Content:    config.ContentConfig{SourceDir: t.TempDir()},
+		Taxonomies: map[string]config.TaxonomyConfig{"series": {}, "épocas": {}},
+	}
+	taxonomies, err := collectTaxonomies(cfg, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, taxonomies, 2) {
+		assert.Equal(t, "Series", taxonomies[0].Title)
+		assert.Equal(t, "Épocas", taxonomies[1].Title)
+	}
+}
@@ -7,73 +7,141 @@ import (
 
 	"github.com/intothevoid/likho/internal/config"
 	"github.com/intothevoid/likho/internal/parser"
+	"github.com/intothevoid/likho/internal/post"
 	"github.com/intothevoid/likho/internal/theme"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// Site is the content parsed during a generation run
+type Site struct {
+	Posts []post.Post
+	Pages []parser.Page
+}
+
+// Generate builds the site into the output directory
 func Generate(cfg *config.Config) error {
+	_, err := Build(cfg)
+	return err
+}
+
+// Build generates the site like Generate and returns the parsed content, for
+// callers such as the server that keep derived data in memory
+func Build(cfg *config.Config) (*Site, error) {
 	logger := utils.GetLogger()
 
 	// Initialize theme manager
 	themeManager, err := theme.NewThemeManager(cfg.Theme.Name, cfg.Content.OutputDir)
 	if err != nil {
-		return fmt.Errorf("failed to initialize theme manager: %v", err)
+		return nil, fmt.Errorf("failed to initialize theme manager: %v", err)
 	}
 
 	// Create the output directory if it doesn't exist
 	if err := os.MkdirAll(cfg.Content.OutputDir, 0755); err != nil {
 		logger.Error("error creating output directory", zap.Error(err))
-		return fmt.Errorf("error creating output directory: %v", err)
+		return nil, fmt.Errorf("error creating output directory: %v", err)
 	}
 
 	// remove the images directory
 	if err := removeImagesDir(cfg.Content.OutputDir); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Remove existing HTML files from the output directory
 	if err := removeGeneratedFiles(cfg.Content.OutputDir); err != nil {
 		logger.Error("error removing existing HTML files", zap.Error(err))
-		return fmt.Errorf("error removing existing HTML files: %v", err)
+		return nil, fmt.Errorf("error removing existing HTML files: %v", err)
 	}
 
 	posts, err := parser.ParsePosts(filepath.Join(cfg.Content.SourceDir, cfg.Content.PostsDir))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	normalizeTags(cfg, posts)
+
+	people, err := loadAuthors(cfg)
+	if err != nil {
+		return nil, err
 	}
+	normalizeAuthors(cfg, people, posts)
 
 	pages, err := parser.ParsePages(filepath.Join(cfg.Content.SourceDir, cfg.Content.PagesDir))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Copy theme assets
 	if err := themeManager.CopyAssets(); err != nil {
-		return fmt.Errorf("failed to copy theme assets: %v", err)
+		return nil, fmt.Errorf("failed to copy theme assets: %v", err)
 	}
 
 	// Update templates directory to use theme templates
 	cfg.Content.TemplatesDir = themeManager.GetTemplatePath()
 
-	if err := generateHTML(cfg, posts, pages); err != nil {
-		return err
+	if cfg.Build.OGImages {
+		if err := generateOGImages(cfg, themeManager, posts); err != nil {
+			return nil, err
+		}
+	} else if err := os.RemoveAll(filepath.Join(cfg.Content.OutputDir, "og")); err != nil {
+		return nil, err
+	}
+
+	imgs, err := processImages(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tocs := renderPosts(cfg, imgs, posts)
+	summarizePosts(cfg, posts)
+	setReadingTimes(cfg, posts)
+
+	galleries, err := loadGalleries(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	authorList := collectAuthors(people, posts)
+
+	layout := siteLayout{Pages: pages, HasGalleries: len(galleries) > 0, Archive: newArchive(cfg, posts)}
+
+	if err := generateHTML(cfg, themeManager, imgs, posts, tocs, authorList, layout); err != nil {
+		return nil, err
+	}
+
+	if err := generateTagPages(cfg, themeManager, imgs, posts, layout); err != nil {
+		return nil, err
+	}
+
+	taxonomies, err := generateTaxonomies(cfg, themeManager, imgs, posts, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := generateAuthorPages(cfg, themeManager, imgs, authorList, layout); err != nil {
+		return nil, err
 	}
 
-	if err := generateTagPages(cfg, posts, pages); err != nil {
-		return err
+	if err := generateGalleries(cfg, themeManager, galleries, layout); err != nil {
+		return nil, err
 	}
 
-	if err := generateSitemap(cfg, posts); err != nil {
-		return err
+	if err := generateSitemap(cfg, posts, galleries, taxonomies, authorList, layout.Archive); err != nil {
+		return nil, err
 	}
 
 	if err := generateRSS(cfg, posts); err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := generateAtom(cfg, posts, authorList); err != nil {
+		return nil, err
 	}
 
 	if err := copyStaticAssets(cfg); err != nil {
-		return err
+		return nil, err
+	}
+
+	if err := postProcess(cfg); err != nil {
+		return nil, err
 	}
 
 	// Add this summary log at the end of the Generate function
@@ -83,5 +151,5 @@ func Generate(cfg *config.Config) error {
 		zap.String("outputDir", cfg.Content.OutputDir),
 		zap.String("theme", cfg.Theme.Name))
 
-	return nil
+	return &Site{Posts: posts, Pages: pages}, nil
 }
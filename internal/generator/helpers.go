@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/images"
+	"github.com/intothevoid/likho/internal/post"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
@@ -35,21 +37,59 @@ func urlize(s string) string {
 	return s
 }
 
-func copyStaticAssets(cfg *config.Config) error {
-	// Copy images directory
+// postURL returns the site-relative URL of a post's page
+func postURL(p post.Post) string {
+	return "/posts/" + PostID(p) + ".html"
+}
+
+// PostID returns the identifier used for a post's page and its comments. It
+// combines the title and the slug, since several posts can share a slug.
+func PostID(p post.Post) string {
+	return urlize(p.Title) + "-" + p.Slug
+}
+
+// processImages resizes the images directory into the output directory and
+// returns the processor, or copies it as is and returns nil when image
+// processing is disabled
+func processImages(cfg *config.Config) (*images.Processor, error) {
 	sourceDir := filepath.Join(cfg.Content.SourceDir, cfg.Content.ImagesDir)
 	destinationDir := filepath.Join(cfg.Content.OutputDir, cfg.Content.ImagesDir)
 
 	// Check if source directory exists
 	if _, err := os.Stat(sourceDir); os.IsNotExist(err) {
 		// Source directory doesn't exist, create destination directory and return
-		return os.MkdirAll(destinationDir, 0755)
+		return nil, os.MkdirAll(destinationDir, 0755)
 	}
 
-	if err := copyDir(sourceDir, destinationDir); err != nil {
-		return err
+	if !cfg.Images.Process {
+		return nil, copyDir(sourceDir, destinationDir)
+	}
+
+	imgs := images.NewProcessor(imageOptions(cfg, sourceDir, destinationDir, "/"+cfg.Content.ImagesDir+"/"))
+	if err := imgs.Process(); err != nil {
+		return nil, err
 	}
+	utils.GetLogger().Info("images processed", zap.String("path", destinationDir))
+	return imgs, nil
+}
+
+// imageOptions returns the image processing options from the configuration
+// for a source directory published at urlPrefix
+func imageOptions(cfg *config.Config, sourceDir, outputDir, urlPrefix string) images.Options {
+	return images.Options{
+		SourceDir: sourceDir,
+		OutputDir: outputDir,
+		URLPrefix: urlPrefix,
+		Widths:    cfg.Images.Widths,
+		MaxWidth:  cfg.Images.MaxWidth,
+		Quality:   cfg.Images.Quality,
+		Sizes:     cfg.Images.Sizes,
+		CacheDir:  filepath.Join(cfg.Build.CacheDir, "images"),
+	}
+}
 
+// copyStaticAssets copies the other directory to the output directory
+func copyStaticAssets(cfg *config.Config) error {
 	// Copy other directory
 	sourceOtherDir := filepath.Join(cfg.Content.SourceDir, cfg.Content.OtherDir)
 	destinationOtherDir := filepath.Join(cfg.Content.OutputDir, cfg.Content.OtherDir)
@@ -183,5 +223,10 @@ func removeImagesDir(outputDir string) error {
 		}
 	}
 
+	// Remove gallery photos
+	if err := os.RemoveAll(filepath.Join(outputDir, "galleries")); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/parser"
+)
+
+// siteLayout is the site-wide content shown in the layout of every page
+type siteLayout struct {
+	// Pages are linked from the header
+	Pages []parser.Page
+	// HasGalleries is set when the site has galleries, so that the header
+	// can link to the listing
+	HasGalleries bool
+	// Archive groups the posts by date, nil when archives are disabled
+	Archive *archive
+}
+
+// layoutData holds the fields used by base.html and the header and footer
+// partials. It is embedded in the data passed to every page template.
+type layoutData struct {
+	SiteTitle    string
+	CurrentYear  int
+	PageTitle    string
+	Pages        []parser.Page
+	HasGalleries bool
+	Archive      *archive
+	Features     config.FeaturesConfig
+	Assets       pageAssets
+	Analytics    *analyticsData
+	Meta         pageMeta
+}
+
+// pageAssets records which vendored front-end libraries and stylesheets a
+// page needs
+type pageAssets struct {
+	Prism   bool
+	Mermaid bool
+	// Figures is set for pages showing figures or galleries, which need
+	// the shared figures stylesheet
+	Figures bool
+}
+
+var codeLanguageRe = regexp.MustCompile(`<code class="language-([^"\s]+)`)
+
+func newLayoutData(cfg *config.Config, pageTitle string, layout siteLayout) layoutData {
+	return layoutData{
+		SiteTitle:    cfg.Site.Title,
+		CurrentYear:  time.Now().Year(),
+		PageTitle:    pageTitle,
+		Pages:        layout.Pages,
+		HasGalleries: layout.HasGalleries,
+		Archive:      layout.Archive,
+		Features:     cfg.Features,
+		Analytics:    newAnalyticsData(cfg),
+		Meta:         newPageMeta(cfg, pageTitle, "", ""),
+	}
+}
+
+// detectAssets inspects rendered HTML for content that needs a front-end
+// library, so that pages without code blocks or diagrams load no scripts
+func detectAssets(cfg *config.Config, html string) pageAssets {
+	a := pageAssets{Figures: strings.Contains(html, "<figure")}
+	for _, m := range codeLanguageRe.FindAllStringSubmatch(html, -1) {
+		if strings.EqualFold(m[1], "mermaid") {
+			a.Mermaid = true
+		} else if cfg.Theme.Features.SyntaxHighlighting {
+			a.Prism = true
+		}
+	}
+	return a
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	mdparser "github.com/gomarkdown/markdown/parser"
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/images"
+	"github.com/intothevoid/likho/internal/post"
+)
+
+// renderedMarkdown is Markdown content converted to HTML
+type renderedMarkdown struct {
+	HTML string
+	// TOC lists the headings between the configured levels
+	TOC tableOfContents
+	// InlineTOC is set when the content places the TOC itself with [TOC]
+	InlineTOC bool
+}
+
+// renderPost converts a post's Markdown content to HTML with syntax
+// highlighting classes, heading anchors and absolute links to images and
+// other files. Images processed by imgs get srcset, sizes and dimensions;
+// imgs may be nil. The table of contents lists the headings between the
+// levels in toc.
+func renderPost(p post.Post, imgs *images.Processor, toc config.TOCConfig) renderedMarkdown {
+	return renderMarkdown(p.Content, imgs, toc)
+}
+
+// renderPosts renders every post once, setting its HTML and Text, and
+// returns the tables of contents shown beside the posts: those turned on
+// for a post and not placed in its content with [TOC].
+func renderPosts(cfg *config.Config, imgs *images.Processor, posts []post.Post) []tableOfContents {
+	tocs := make([]tableOfContents, len(posts))
+	for i := range posts {
+		rendered := renderPost(posts[i], imgs, cfg.TOC)
+		posts[i].HTML = template.HTML(rendered.HTML)
+		posts[i].Text = htmlText(rendered.HTML)
+		if showTOC(cfg, posts[i]) && !rendered.InlineTOC {
+			tocs[i] = rendered.TOC
+		}
+	}
+	return tocs
+}
+
+// postHTML returns the rendered content of a post, rendering it without
+// image processing when renderPosts has not
+func postHTML(p post.Post) string {
+	if p.HTML != "" {
+		return string(p.HTML)
+	}
+	return renderPost(p, nil, config.TOCConfig{}).HTML
+}
+
+// postText returns the readable text of a post like postHTML
+func postText(p post.Post) string {
+	if p.HTML != "" {
+		return p.Text
+	}
+	return htmlText(postHTML(p))
+}
+
+// renderMarkdown converts Markdown content to HTML like renderPost
+func renderMarkdown(content string, imgs *images.Processor, tocCfg config.TOCConfig) renderedMarkdown {
+	doc := markdown.Parse([]byte(content), newMarkdownParser())
+	parseImageAttributes(doc)
+	r := renderedMarkdown{TOC: buildTOC(doc, tocCfg)}
+
+	htmlFlags := mdhtml.CommonFlags | mdhtml.HrefTargetBlank
+	opts := mdhtml.RendererOptions{
+		Flags:          htmlFlags,
+		RenderNodeHook: renderHook(imgs, &r),
+	}
+	renderer := mdhtml.NewRenderer(opts)
+
+	html := markdown.Render(doc, renderer)
+
+	// Convert relative image paths to absolute paths in HTML
+	htmlStr := string(html)
+	htmlStr = strings.ReplaceAll(htmlStr, "src=\"images/", "src=\"/images/")
+	htmlStr = strings.ReplaceAll(htmlStr, "src=\"../images/", "src=\"/images/")
+	htmlStr = strings.ReplaceAll(htmlStr, "src=\"./images/", "src=\"/images/")
+	htmlStr = strings.ReplaceAll(htmlStr, "href=\"images/", "href=\"/images/")
+	htmlStr = strings.ReplaceAll(htmlStr, "href=\"../images/", "href=\"/images/")
+	htmlStr = strings.ReplaceAll(htmlStr, "href=\"./images/", "href=\"/images/")
+
+	// Convert relative links to files in other directory to absolute paths
+	htmlStr = strings.ReplaceAll(htmlStr, "href=\"other/", "href=\"/other/")
+	htmlStr = strings.ReplaceAll(htmlStr, "href=\"../other/", "href=\"/other/")
+	htmlStr = strings.ReplaceAll(htmlStr, "href=\"./other/", "href=\"/other/")
+
+	r.HTML = htmlStr
+	return r
+}
+
+// renderHook adds self-links to headings, replaces a [TOC] paragraph with
+// r's table of contents and renders images with imageHook
+func renderHook(imgs *images.Processor, r *renderedMarkdown) mdhtml.RenderNodeFunc {
+	renderImages := imageHook(imgs)
+	return func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+		switch node := node.(type) {
+		case *ast.Heading:
+			// The anchor goes before the closing tag written by the renderer
+			if !entering && node.HeadingID != "" {
+				writeHeadingAnchor(w, node)
+			}
+			return ast.GoToNext, false
+		case *ast.Paragraph:
+			if isTOCMarker(node) {
+				if entering {
+					io.WriteString(w, string(r.TOC.HTML()))
+					r.InlineTOC = true
+				}
+				return ast.SkipChildren, true
+			}
+		}
+		return renderImages(w, node, entering)
+	}
+}
+
+// newMarkdownParser returns a parser with the extensions used for posts
+func newMarkdownParser() *mdparser.Parser {
+	return mdparser.NewWithExtensions(mdparser.CommonExtensions | mdparser.Attributes)
+}
+
+// nodeText returns the plain text inside a node, such as an image's alt text
+func nodeText(node ast.Node) string {
+	var b strings.Builder
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if leaf := n.AsLeaf(); leaf != nil && entering {
+			b.Write(leaf.Literal)
+		}
+		return ast.GoToNext
+	})
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPosts(t *testing.T) {
+	cfg := &config.Config{TOC: config.TOCConfig{Enabled: true, MinLevel: 2, MaxLevel: 3}}
+	off := false
+	posts := []post.Post{
+		{Content: "## One\n\nSome text.\n\n```\ncode\n```\n"},
+		{Content: "[TOC]\n\n## Two\n\nMore text.\n"},
+		{Content: "## Three\n", TOC: &off},
+	}
+
+	tocs := renderPosts(cfg, nil, posts)
+	assert.Contains(t, string(posts[0].HTML), `<h2 id="one">`)
+	assert.Equal(t, "One # Some text.", posts[0].Text)
+	if assert.Len(t, tocs, 3) {
+		assert.Len(t, tocs[0], 1)
+		assert.Empty(t, tocs[1], "an inline table of contents is not repeated")
+		assert.Empty(t, tocs[2], "turned off by the post")
+	}
+	assert.Contains(t, string(posts[1].HTML), `<nav class="toc"`)
+	assert.Equal(t, "Two # More text.", posts[1].Text)
+
+	assert.Equal(t, string(posts[0].HTML), postHTML(posts[0]))
+	assert.Equal(t, posts[0].Text, postText(posts[0]))
+}
+
+func TestPostTextWithoutRendering(t *testing.T) {
+	p := post.Post{Content: "Hello *world*"}
+	assert.Equal(t, "<p>Hello <em>world</em></p>\n", postHTML(p))
+	assert.Equal(t, "Hello world", postText(p))
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/parser"
+	"github.com/intothevoid/likho/internal/post"
+)
+
+// descriptionWords is the length of the description derived from the content
+// of posts that have none in their front matter
+const descriptionWords = 30
+
+// pageMeta holds the fields used by the meta partial for the canonical URL,
+// Open Graph and Twitter card tags and JSON-LD
+type pageMeta struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	ImageWidth  int
+	ImageHeight int
+	Type        string
+	SiteName    string
+	Twitter     string
+	Published   string
+	Tags        []string
+	NoIndex     bool
+	JSONLD      interface{}
+}
+
+// blogPosting is the schema.org BlogPosting emitted as JSON-LD for posts
+type blogPosting struct {
+	Context          string     `json:"@context"`
+	Type             string     `json:"@type"`
+	Headline         string     `json:"headline"`
+	Description      string     `json:"description,omitempty"`
+	URL              string     `json:"url"`
+	MainEntityOfPage string     `json:"mainEntityOfPage"`
+	Image            string     `json:"image,omitempty"`
+	DatePublished    string     `json:"datePublished"`
+	DateModified     string     `json:"dateModified"`
+	Author           []ldPerson `json:"author,omitempty"`
+	Keywords         string     `json:"keywords,omitempty"`
+	WordCount        int        `json:"wordCount,omitempty"`
+	TimeRequired     string     `json:"timeRequired,omitempty"`
+}
+
+type ldPerson struct {
+	Type        string   `json:"@type"`
+	Name        string   `json:"name"`
+	URL         string   `json:"url,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Description string   `json:"description,omitempty"`
+	SameAs      []string `json:"sameAs,omitempty"`
+}
+
+// profilePage is the schema.org ProfilePage emitted as JSON-LD for author
+// pages
+type profilePage struct {
+	Context    string   `json:"@context"`
+	Type       string   `json:"@type"`
+	URL        string   `json:"url"`
+	MainEntity ldPerson `json:"mainEntity"`
+}
+
+// newPageMeta returns the metadata for a page at the site-relative urlPath.
+// An empty urlPath leaves out the canonical URL.
+func newPageMeta(cfg *config.Config, title, description, urlPath string) pageMeta {
+	if description == "" {
+		description = cfg.Site.Description
+	}
+	m := pageMeta{
+		Title:       title,
+		Description: description,
+		Type:        "website",
+		SiteName:    cfg.Site.Title,
+		Twitter:     twitterHandle(cfg.Social.Twitter),
+	}
+	if urlPath != "" {
+		m.URL = absURL(cfg, urlPath)
+	}
+	return m
+}
+
+// newPostMeta returns the metadata for a post by authors, describing it as
+// an article.
+// Posts without a description use the start of their rendered text.
+func newPostMeta(cfg *config.Config, p post.Post, authors []*author) pageMeta {
+	description := p.Description
+	if description == "" {
+		description = truncateWords(postText(p), descriptionWords)
+	}
+
+	m := newPageMeta(cfg, p.Title, description, postURL(p))
+	m.Type = "article"
+	m.Published = p.Date.Format(time.RFC3339)
+	m.Tags = p.Tags
+	if p.FeaturedImage != "" {
+		m.Image = absURL(cfg, p.FeaturedImage)
+	} else if cfg.Build.OGImages {
+		m.Image = absURL(cfg, ogImageURL(p))
+		m.ImageWidth, m.ImageHeight = ogImageWidth, ogImageHeight
+	}
+
+	ld := blogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         p.Title,
+		Description:      m.Description,
+		URL:              m.URL,
+		MainEntityOfPage: m.URL,
+		Image:            m.Image,
+		DatePublished:    m.Published,
+		DateModified:     m.Published,
+		Keywords:         strings.Join(p.Tags, ", "),
+		WordCount:        p.WordCount,
+	}
+	if p.ReadingTime > 0 {
+		ld.TimeRequired = fmt.Sprintf("PT%dM", p.ReadingTime)
+	}
+	for _, a := range authors {
+		ld.Author = append(ld.Author, ldPerson{Type: "Person", Name: a.Name, URL: absURL(cfg, a.URL)})
+	}
+	m.JSONLD = ld
+	return m
+}
+
+// newAuthorMeta returns the metadata for an author's page, describing the
+// author as a person
+func newAuthorMeta(cfg *config.Config, a *author) pageMeta {
+	m := newPageMeta(cfg, a.Name, a.Bio, a.URL)
+	m.Type = "profile"
+	if a.Avatar != "" {
+		m.Image = absURL(cfg, a.Avatar)
+	}
+
+	person := ldPerson{Type: "Person", Name: a.Name, URL: m.URL, Image: m.Image, Description: a.Bio}
+	for _, l := range a.Links {
+		person.SameAs = append(person.SameAs, l.URL)
+	}
+	m.JSONLD = profilePage{Context: "https://schema.org", Type: "ProfilePage", URL: m.URL, MainEntity: person}
+	return m
+}
+
+// newStaticPageMeta returns the metadata for a page from the pages directory
+func newStaticPageMeta(cfg *config.Config, page parser.Page) pageMeta {
+	m := newPageMeta(cfg, page.Title, page.Description, "/pages/"+page.Slug+".html")
+	if page.FeaturedImage != "" {
+		m.Image = absURL(cfg, page.FeaturedImage)
+	}
+	return m
+}
+
+// absURL resolves a site-relative path or a path relative to the content
+// directory against the site's base URL. Absolute URLs are returned as is.
+func absURL(cfg *config.Config, s string) string {
+	if u, err := url.Parse(s); err == nil && u.IsAbs() {
+		return s
+	}
+	s = strings.TrimPrefix(s, "./")
+	for strings.HasPrefix(s, "../") {
+		s = strings.TrimPrefix(s, "../")
+	}
+	return strings.TrimRight(cfg.Site.BaseURL, "/") + "/" + strings.TrimLeft(s, "/")
+}
+
+// twitterHandle returns the @handle from a Twitter or X profile URL
+func twitterHandle(profile string) string {
+	if strings.HasPrefix(profile, "@") {
+		return profile
+	}
+	u, err := url.Parse(profile)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	handle := strings.Trim(u.Path, "/")
+	if handle == "" || strings.Contains(handle, "/") {
+		return ""
+	}
+	return "@" + handle
+}
+
+// authorName returns the name from an author given as "Name <email>"
+func authorName(author string) string {
+	if addr, err := mail.ParseAddress(author); err == nil && addr.Name != "" {
+		return addr.Name
+	}
+	return strings.TrimSpace(author)
+}
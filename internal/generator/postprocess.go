@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/theme"
+	"github.com/intothevoid/likho/pkg/utils"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/html"
+	"github.com/tdewolff/minify/v2/js"
+	"github.com/tdewolff/minify/v2/xml"
+	"go.uber.org/zap"
+)
+
+// minifyTypes maps the extensions handled by the minifier to their media types
+var minifyTypes = map[string]string{
+	".html": "text/html",
+	".xml":  "text/xml",
+	".css":  "text/css",
+	".js":   "application/javascript",
+}
+
+// compressibleExts lists the text formats that are worth precompressing
+var compressibleExts = map[string]bool{
+	".html": true,
+	".xml":  true,
+	".css":  true,
+	".js":   true,
+	".json": true,
+	".svg":  true,
+	".txt":  true,
+}
+
+// postProcess minifies and precompresses the generated files according to the
+// build configuration. Files copied verbatim from the content directory are
+// left untouched.
+func postProcess(cfg *config.Config) error {
+	logger := utils.GetLogger()
+
+	m := minify.New()
+	m.Add("text/html", &html.Minifier{KeepDocumentTags: true, KeepEndTags: true})
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+	m.AddFuncRegexp(regexp.MustCompile("[/+]xml$"), xml.Minify)
+
+	otherDir := filepath.Join(cfg.Content.OutputDir, cfg.Content.OtherDir)
+	minified, compressed := 0, 0
+
+	// Compressed siblings are always rebuilt so they never go stale
+	err := filepath.Walk(cfg.Content.OutputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path == otherDir {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".gz" {
+			return os.Remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = filepath.Walk(cfg.Content.OutputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path == otherDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		mediaType, ok := minifyTypes[ext]
+		if cfg.Build.Minify && ok && !strings.Contains(filepath.Base(path), ".min.") && !theme.IsFingerprinted(path) {
+			out, err := m.Bytes(mediaType, data)
+			if err != nil {
+				return fmt.Errorf("error minifying %s: %v", path, err)
+			}
+			if !bytes.Equal(out, data) {
+				if err := os.WriteFile(path, out, info.Mode()); err != nil {
+					return fmt.Errorf("error writing minified file %s: %v", path, err)
+				}
+				data = out
+				minified++
+			}
+		}
+
+		if cfg.Build.Precompress && compressibleExts[ext] && len(data) >= cfg.Build.PrecompressMinSize {
+			if err := writeGzip(path+".gz", data); err != nil {
+				return fmt.Errorf("error precompressing %s: %v", path, err)
+			}
+			compressed++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.Build.Minify || cfg.Build.Precompress {
+		logger.Info("output post-processed", zap.Int("minified", minified), zap.Int("precompressed", compressed))
+	}
+	return nil
+}
+
+func writeGzip(path string, data []byte) error {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
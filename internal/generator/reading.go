@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/search"
+)
+
+// setReadingTimes sets the word count and reading time of every post
+func setReadingTimes(cfg *config.Config, posts []post.Post) {
+	for i := range posts {
+		words := countWords(postText(posts[i]))
+		posts[i].WordCount = words
+		posts[i].ReadingTime = readingTime(words, cfg.Content.WordsPerMinute)
+	}
+}
+
+// countWords counts the words in text. Each Chinese, Japanese or Korean
+// character counts as a word, as in the search index. Tokens
+// without a letter or digit, such as a lone dash, are not counted, while
+// punctuation inside a word such as "don't" does not split it.
+func countWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		word := false
+		for _, r := range field {
+			switch {
+			case search.IsCJK(r):
+				if word {
+					count++
+					word = false
+				}
+				count++
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				word = true
+			}
+		}
+		if word {
+			count++
+		}
+	}
+	return count
+}
+
+// readingTime returns the minutes needed to read a number of words, rounded
+// up and at least one
+func readingTime(words, wordsPerMinute int) int {
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = 200
+	}
+	return max(1, (words+wordsPerMinute-1)/wordsPerMinute)
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"spaces", "  \n\t ", 0},
+		{"english", "The quick brown fox jumps.", 5},
+		{"apostrophe", "Don't split contractions", 3},
+		{"lone punctuation", "before - after ... end", 3},
+		{"numbers", "Go 1.23 was released in 2024", 6},
+		{"accents", "Café crème à emporter", 4},
+		{"chinese", "你好世界", 4},
+		{"japanese", "ひらがなとカタカナ", 9},
+		{"korean", "안녕 세상", 4},
+		{"mixed", "Go言語 is fun", 5},
+		{"cjk punctuation", "你好，世界。", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, countWords(tt.text))
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words, wpm, want int
+	}{
+		{0, 200, 1},
+		{1, 200, 1},
+		{200, 200, 1},
+		{201, 200, 2},
+		{1000, 250, 4},
+		{450, 0, 3},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, readingTime(tt.words, tt.wpm), "%d words at %d wpm", tt.words, tt.wpm)
+	}
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// slugSymbols spells out the symbols that tell names such as C++ and C#
+// apart
+var slugSymbols = map[rune]string{
+	'+': "plus",
+	'#': "sharp",
+	'&': "and",
+	'@': "at",
+}
+
+// nameKey returns the identity of a name such as a taxonomy term. Names
+// differing only in case or in the spaces, hyphens and underscores between
+// their words, such as "Machine Learning" and "machine-learning", share a
+// key; "C", "C++" and "C#" do not.
+func nameKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '_'
+	})
+	if len(words) == 0 {
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	return strings.Join(words, "-")
+}
+
+// slugify returns the file name, without extension, of the page of a name.
+// Letters and digits of every script are kept, lowercased, with words joined
+// by hyphens, so names with the same key share a slug. When other
+// characters are dropped or spelled out, a hash of the key is appended so
+// that names with different keys never share a slug.
+func slugify(name string) string {
+	key := nameKey(name)
+
+	var b strings.Builder
+	lossless := true
+	hyphen := false
+	for _, r := range key {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		case r == '-':
+			hyphen = true
+		default:
+			lossless = false
+			if word, ok := slugSymbols[r]; ok {
+				if b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				b.WriteString(word)
+			}
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if lossless && slug != "" {
+		return slug
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if slug == "" {
+		return fmt.Sprintf("%08x", h.Sum32())
+	}
+	return fmt.Sprintf("%s-%08x", slug, h.Sum32())
+}
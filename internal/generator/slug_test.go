@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Go", "go"},
+		{"  Machine   Learning ", "machine-learning"},
+		{"machine-learning", "machine-learning"},
+		{"machine_learning", "machine-learning"},
+		{"C++", "c++"},
+		{"C#", "c#"},
+		{"日本語", "日本語"},
+		{"José Núñez", "josé-núñez"},
+		{"---", "---"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nameKey(tt.name))
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Go", "go"},
+		{"Machine Learning", "machine-learning"},
+		{"machine_learning", "machine-learning"},
+		{"Go 1.23", "go-1-23-7687f362"},
+		{"日本語", "日本語"},
+		{"中文 编程", "中文-编程"},
+		{"José Núñez", "josé-núñez"},
+		{"Привет", "привет"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slugify(tt.name))
+		})
+	}
+
+	// Names differing in more than case and separators never share a slug
+	names := []string{"C", "C++", "C#", "c plus plus", "c sharp", "Node.js", "NodeJS", "!!!", "???", "---"}
+	slugs := make(map[string]string)
+	for _, name := range names {
+		slug := slugify(name)
+		assert.NotEmpty(t, slug, name)
+		if other, ok := slugs[slug]; ok {
+			t.Errorf("%q and %q share the slug %q", other, name, slug)
+		}
+		slugs[slug] = name
+	}
+	assert.Regexp(t, `^c-plus-plus-[0-9a-f]{8}$`, slugify("C++"))
+	assert.Regexp(t, `^c-sharp-[0-9a-f]{8}$`, slugify("C#"))
+	assert.Equal(t, slugify("C++"), slugify("c++"))
+}
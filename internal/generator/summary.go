@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/search"
+)
+
+// moreMarker matches the <!--more--> separator ending a post's summary
+var moreMarker = regexp.MustCompile(`<!--\s*more\s*-->`)
+
+// tocNav matches a table of contents placed in the content with [TOC],
+// which is left out of summaries
+var tocNav = regexp.MustCompile(`(?s)<nav class="toc"[^>]*>.*?</nav>\n?`)
+
+// htmlTag matches an HTML tag or comment
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// headingAnchor matches the self-links added to headings, which are left
+// out of summaries
+var headingAnchor = regexp.MustCompile(` <a class="heading-anchor"[^>]*>#</a>`)
+
+// voidElements are elements without a closing tag
+var voidElements = map[string]bool{
+	"area": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// summarizePosts sets the summary of every post
+func summarizePosts(cfg *config.Config, posts []post.Post) {
+	for i := range posts {
+		posts[i].Summary, posts[i].Truncated = summarize(posts[i], cfg.Content.SummaryWords)
+	}
+}
+
+// summarize returns the HTML of a post's content up to the <!--more-->
+// separator or, without one, its first n words. The separator is found in
+// the rendered content, where one inside a code block has been escaped.
+// The flag reports whether the summary leaves out part of the post.
+func summarize(p post.Post, n int) (template.HTML, bool) {
+	content := headingAnchor.ReplaceAllString(postHTML(p), "")
+	content = tocNav.ReplaceAllString(content, "")
+
+	var summary string
+	var truncated bool
+	if loc := moreMarker.FindStringIndex(content); loc != nil {
+		summary, truncated = cutHTML(content[:loc[0]], content[loc[1]:])
+	} else {
+		summary, truncated = truncateHTML(content, n)
+	}
+	return template.HTML(strings.TrimSpace(summary)), truncated
+}
+
+// cutHTML closes the elements left open in the HTML before a cut. It
+// reports whether the HTML after the cut holds more than their closing tags.
+func cutHTML(before, after string) (string, bool) {
+	var open []string
+	for _, tag := range htmlTag.FindAllString(before, -1) {
+		open = trackElement(open, tag)
+	}
+	rest := strings.TrimSpace(after)
+	for j := len(open) - 1; j >= 0; j-- {
+		before = strings.TrimRightFunc(before, unicode.IsSpace) + "</" + open[j] + ">"
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "</"+open[j]+">"))
+	}
+	return before, rest != ""
+}
+
+// truncateHTML cuts HTML after its first n words of text, adding an
+// ellipsis and closing the elements left open. As in countWords, each
+// Chinese, Japanese or Korean character counts as a word. It reports whether words
+// were dropped.
+func truncateHTML(s string, n int) (string, bool) {
+	var b strings.Builder
+	var open []string
+	words := 0
+	inWord := false
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			gt := strings.IndexByte(s[i:], '>')
+			if gt < 0 {
+				break
+			}
+			tag := s[i : i+gt+1]
+			b.WriteString(tag)
+			i += gt + 1
+			open = trackElement(open, tag)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		start := false
+		switch {
+		case unicode.IsSpace(r):
+			inWord = false
+		case search.IsCJK(r):
+			inWord = false
+			start = true
+		case !inWord:
+			inWord = true
+			start = true
+		}
+		if start {
+			words++
+			if words > n {
+				out := strings.TrimRightFunc(b.String(), unicode.IsSpace) + "…"
+				for j := len(open) - 1; j >= 0; j-- {
+					out += "</" + open[j] + ">"
+				}
+				return out, true
+			}
+		}
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return s, false
+}
+
+// trackElement updates the stack of open elements for a tag
+func trackElement(open []string, tag string) []string {
+	if strings.HasPrefix(tag, "<!") || strings.HasSuffix(tag, "/>") {
+		return open
+	}
+	closing := strings.HasPrefix(tag, "</")
+	name := strings.TrimPrefix(strings.TrimPrefix(tag[:len(tag)-1], "<"), "/")
+	if i := strings.IndexAny(name, " \t\n"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToLower(name)
+	if voidElements[name] {
+		return open
+	}
+	if !closing {
+		return append(open, name)
+	}
+	for j := len(open) - 1; j >= 0; j-- {
+		if open[j] == name {
+			return open[:j]
+		}
+	}
+	return open
+}
@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateHTML(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		n         int
+		want      string
+		truncated bool
+	}{
+		{
+			name: "short text is kept",
+			html: "<p>Just a few words.</p>",
+			n:    10,
+			want: "<p>Just a few words.</p>",
+		},
+		{
+			name: "exact length is kept",
+			html: "<p>one two three</p>",
+			n:    3,
+			want: "<p>one two three</p>",
+		},
+		{
+			name:      "paragraph is closed",
+			html:      "<p>one two three four</p>",
+			n:         2,
+			want:      "<p>one two…</p>",
+			truncated: true,
+		},
+		{
+			name:      "nested elements are closed in order",
+			html:      "<p>one <em>two <strong>three four</strong></em> five</p>",
+			n:         3,
+			want:      "<p>one <em>two <strong>three…</strong></em></p>",
+			truncated: true,
+		},
+		{
+			name:      "later blocks are dropped",
+			html:      "<p>one two</p>\n<p>three four</p>",
+			n:         2,
+			want:      "<p>one two</p>\n<p>…</p>",
+			truncated: true,
+		},
+		{
+			name:      "attributes do not count as words",
+			html:      `<p><a href="/a b c" title="x y z">one two</a> three</p>`,
+			n:         2,
+			want:      `<p><a href="/a b c" title="x y z">one two</a>…</p>`,
+			truncated: true,
+		},
+		{
+			name:      "void elements are not closed",
+			html:      "<p>one<br>\ntwo <img src=\"x.png\" alt=\"\"> three</p>",
+			n:         2,
+			want:      "<p>one<br>\ntwo <img src=\"x.png\" alt=\"\">…</p>",
+			truncated: true,
+		},
+		{
+			name:      "entities stay whole",
+			html:      "<p>fish &amp; chips tonight</p>",
+			n:         3,
+			want:      "<p>fish &amp; chips…</p>",
+			truncated: true,
+		},
+		{
+			name:      "chinese characters are words",
+			html:      "<p>我们今天学习汉字</p>",
+			n:         4,
+			want:      "<p>我们今天…</p>",
+			truncated: true,
+		},
+		{
+			name:      "korean syllables are words",
+			html:      "<p>안녕 세상</p>",
+			n:         3,
+			want:      "<p>안녕 세…</p>",
+			truncated: true,
+		},
+		{
+			name:      "mixed scripts",
+			html:      "<p>Go言語の本 is great</p>",
+			n:         3,
+			want:      "<p>Go言語…</p>",
+			truncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncateHTML(tt.html, tt.n)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.truncated, truncated)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		n         int
+		want      string
+		truncated bool
+	}{
+		{
+			name:    "short post",
+			content: "Intro\n\nBody\n",
+			n:       10,
+			want:    "<p>Intro</p>\n\n<p>Body</p>",
+		},
+		{
+			name:      "first words",
+			content:   "one two three four\n",
+			n:         2,
+			want:      "<p>one two…</p>",
+			truncated: true,
+		},
+		{
+			name:      "separator",
+			content:   "Intro\n\n<!--more-->\n\nBody\n",
+			n:         10,
+			want:      "<p>Intro</p>",
+			truncated: true,
+		},
+		{
+			name:      "separator inside a paragraph",
+			content:   "Intro <!-- more --> Body\n",
+			n:         10,
+			want:      "<p>Intro</p>",
+			truncated: true,
+		},
+		{
+			name:    "separator ending the post",
+			content: "Intro\n\n<!--more-->\n",
+			n:       10,
+			want:    "<p>Intro</p>",
+		},
+		{
+			name:    "separator in a backtick fence is skipped",
+			content: "Intro\n\n```html\n<!--more-->\n```\n",
+			n:       10,
+			want:    "<p>Intro</p>\n\n<pre><code class=\"language-html\">&lt;!--more--&gt;\n</code></pre>",
+		},
+		{
+			name:      "separator after a fence",
+			content:   "```\n<!--more-->\n```\n\nMore\n\n<!--more-->\n\nBody\n",
+			n:         10,
+			want:      "<pre><code>&lt;!--more--&gt;\n</code></pre>\n\n<p>More</p>",
+			truncated: true,
+		},
+		{
+			name:    "separator in a tilde fence is skipped",
+			content: "Intro\n\n~~~\n<!--more-->\n~~~\n",
+			n:       10,
+			want:    "<p>Intro</p>\n\n<pre><code>&lt;!--more--&gt;\n</code></pre>",
+		},
+		{
+			name:    "heading anchors are left out",
+			content: "# Title\n\nIntro\n",
+			n:       10,
+			want:    "<h1 id=\"title\">Title</h1>\n\n<p>Intro</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, truncated := summarize(post.Post{Content: tt.content}, tt.n)
+			assert.Equal(t, tt.want, string(summary))
+			assert.Equal(t, tt.truncated, truncated)
+		})
+	}
+}
+
+func TestSummarizeRenderedHTML(t *testing.T) {
+	p := post.Post{Content: "Markdown", HTML: "<p>Rendered <!--more--> once</p>"}
+	summary, truncated := summarize(p, 10)
+	assert.Equal(t, "<p>Rendered</p>", string(summary))
+	assert.True(t, truncated)
+
+	p.HTML = "<nav class=\"toc\" aria-label=\"Table of contents\"><ul><li><a href=\"#a\">A</a></li></ul></nav>\n<p>Intro</p>"
+	summary, truncated = summarize(p, 10)
+	assert.Equal(t, "<p>Intro</p>", string(summary))
+	assert.False(t, truncated)
+}
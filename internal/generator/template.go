@@ -1,14 +1,68 @@
 package generator
 
 import (
+	"embed"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
 
+	"github.com/intothevoid/likho/internal/config"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
 
+// builtinTemplates holds fallback page templates used when a theme does not
+// provide its own
+//
+//go:embed templates
+var builtinTemplates embed.FS
+
+// parseTemplates parses the theme's base, header and footer templates together
+// with the named page template. If the theme has no such page template, the
+// built-in one is used instead. The built-in partials are parsed first so a
+// theme can override any of them with a file of the same name.
+func parseTemplates(cfg *config.Config, funcMap template.FuncMap, page string) (*template.Template, error) {
+	files := []string{
+		filepath.Join(cfg.Content.TemplatesDir, "base.html"),
+		filepath.Join(cfg.Content.TemplatesDir, page),
+		filepath.Join(cfg.Content.TemplatesDir, "header.html"),
+		filepath.Join(cfg.Content.TemplatesDir, "footer.html"),
+	}
+
+	tmpl := template.New("").Funcs(funcMap)
+	partials, err := fs.Glob(builtinTemplates, "templates/partials/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("error listing built-in partials: %v", err)
+	}
+	for _, partial := range partials {
+		if _, err := tmpl.ParseFS(builtinTemplates, partial); err != nil {
+			return nil, fmt.Errorf("error parsing built-in partial %s: %v", partial, err)
+		}
+		themePartial := filepath.Join(cfg.Content.TemplatesDir, path.Base(partial))
+		if _, err := os.Stat(themePartial); err == nil {
+			files = append(files, themePartial)
+		}
+	}
+
+	if _, err := os.Stat(files[1]); os.IsNotExist(err) {
+		if _, err := builtinTemplates.Open("templates/" + page); err != nil {
+			return nil, fmt.Errorf("error parsing templates: template %s not found", page)
+		}
+		if _, err := tmpl.ParseFS(builtinTemplates, "templates/"+page); err != nil {
+			return nil, fmt.Errorf("error parsing built-in template %s: %v", page, err)
+		}
+		files = append(files[:1], files[2:]...)
+	}
+
+	if _, err := tmpl.ParseFiles(files...); err != nil {
+		return nil, fmt.Errorf("error parsing templates: %v", err)
+	}
+	return tmpl, nil
+}
+
 func executeTemplate(tmpl *template.Template, name, outputPath string, data interface{}) error {
 	logger := utils.GetLogger()
 
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"html"
+	"strings"
+)
+
+// skippedElements are elements whose content is not readable text
+var skippedElements = map[string]bool{
+	"nav":    true,
+	"pre":    true,
+	"script": true,
+	"style":  true,
+}
+
+// htmlText extracts the readable text from rendered HTML, collapsing
+// whitespace. Code blocks and tables of contents are skipped as they add
+// noise to search indexes and word counts.
+func htmlText(s string) string {
+	var b strings.Builder
+	skip := ""
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			if skip == "" {
+				b.WriteString(s)
+			}
+			break
+		}
+		if skip == "" {
+			b.WriteString(s[:lt])
+		}
+		gt := strings.IndexByte(s[lt:], '>')
+		if gt < 0 {
+			break
+		}
+		tag := s[lt+1 : lt+gt]
+		s = s[lt+gt+1:]
+		b.WriteByte(' ')
+
+		name := strings.TrimPrefix(tag, "/")
+		if i := strings.IndexAny(name, " \t\n/"); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.ToLower(name)
+		switch {
+		case skip == "" && skippedElements[name] && !strings.HasPrefix(tag, "/"):
+			skip = name
+		case skip == name && strings.HasPrefix(tag, "/"):
+			skip = ""
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// truncateWords returns the first n words of text, adding an ellipsis when
+// words were dropped
+func truncateWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:n], " ") + "…"
+}
@@ -0,0 +1,157 @@
+package generator
+
+import (
+	"html"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+)
+
+// tocMarker is replaced by the table of contents when it is the only text
+// of a paragraph
+const tocMarker = "[TOC]"
+
+// tocEntry is a heading listed in a table of contents
+type tocEntry struct {
+	ID       string
+	Title    string
+	Level    int
+	Children []tocEntry
+}
+
+// tableOfContents lists a post's headings, nested by level
+type tableOfContents []tocEntry
+
+// HTML renders the table of contents as nested lists inside a <nav>, or
+// nothing when it is empty
+func (toc tableOfContents) HTML() template.HTML {
+	if len(toc) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<nav class="toc" aria-label="Table of contents">`)
+	writeTOCList(&b, toc)
+	b.WriteString("</nav>\n")
+	return template.HTML(b.String())
+}
+
+func writeTOCList(w io.StringWriter, entries []tocEntry) {
+	w.WriteString("<ul>")
+	for _, e := range entries {
+		w.WriteString(`<li><a href="#` + html.EscapeString(e.ID) + `">` + html.EscapeString(e.Title) + "</a>")
+		if len(e.Children) > 0 {
+			writeTOCList(w, e.Children)
+		}
+		w.WriteString("</li>")
+	}
+	w.WriteString("</ul>")
+}
+
+// showTOC reports whether a post's page shows its table of contents
+func showTOC(cfg *config.Config, p post.Post) bool {
+	if p.TOC != nil {
+		return *p.TOC
+	}
+	return cfg.TOC.Enabled
+}
+
+// buildTOC gives every heading in a document a unique ID and returns the
+// headings between the configured levels as a table of contents. Explicit
+// IDs such as {#intro} are kept; other IDs are derived from the heading's
+// text.
+func buildTOC(doc ast.Node, cfg config.TOCConfig) tableOfContents {
+	var headings []*ast.Heading
+	used := make(map[string]bool)
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if h, ok := node.(*ast.Heading); ok && entering && !h.IsTitleblock {
+			headings = append(headings, h)
+			if h.HeadingID != "" {
+				used[h.HeadingID] = true
+			}
+		}
+		return ast.GoToNext
+	})
+
+	var entries []tocEntry
+	for _, h := range headings {
+		if h.HeadingID == "" {
+			h.HeadingID = uniqueID(headingID(nodeText(h)), used)
+		}
+		if h.Level >= cfg.MinLevel && h.Level <= cfg.MaxLevel {
+			entries = append(entries, tocEntry{ID: h.HeadingID, Title: nodeText(h), Level: h.Level})
+		}
+	}
+	return nestTOC(entries)
+}
+
+// nestTOC makes the entries following a heading that have a deeper level
+// its children
+func nestTOC(entries []tocEntry) tableOfContents {
+	var toc tableOfContents
+	for i := 0; i < len(entries); {
+		e := entries[i]
+		j := i + 1
+		for j < len(entries) && entries[j].Level > e.Level {
+			j++
+		}
+		e.Children = nestTOC(entries[i+1 : j])
+		toc = append(toc, e)
+		i = j
+	}
+	return toc
+}
+
+// headingID turns heading text into an ID: letters and digits of any
+// script in lower case, with words joined by hyphens
+func headingID(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			hyphen = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+// uniqueID returns id, or id with the first free numeric suffix if it is
+// already used, and marks the result as used
+func uniqueID(id string, used map[string]bool) string {
+	unique := id
+	for i := 1; used[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// isTOCMarker reports whether a paragraph holds only the [TOC] marker
+func isTOCMarker(para *ast.Paragraph) bool {
+	for _, child := range para.Children {
+		if _, ok := child.(*ast.Text); !ok {
+			return false
+		}
+	}
+	return strings.TrimSpace(nodeText(para)) == tocMarker
+}
+
+// writeHeadingAnchor writes the self-link placed at the end of a heading
+func writeHeadingAnchor(w io.Writer, h *ast.Heading) {
+	io.WriteString(w, ` <a class="heading-anchor" href="#`+html.EscapeString(h.HeadingID)+`" aria-label="Link to this section">#</a>`)
+}
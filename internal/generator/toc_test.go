@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadingID(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Getting Started", "getting-started"},
+		{"  Leading and   trailing  ", "leading-and-trailing"},
+		{"What's new in v1.2?", "whats-new-in-v12"},
+		{"snake_case stays", "snake_case-stays"},
+		{"Café au lait", "café-au-lait"},
+		{"Über uns", "über-uns"},
+		{"日本語の見出し", "日本語の見出し"},
+		{"Привет, мир", "привет-мир"},
+		{"C++ & C#", "c-c"},
+		{"--- !!! ---", "section"},
+		{"", "section"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, headingID(tt.text))
+		})
+	}
+}
+
+func TestUniqueID(t *testing.T) {
+	used := make(map[string]bool)
+	assert.Equal(t, "intro", uniqueID("intro", used))
+	assert.Equal(t, "intro-1", uniqueID("intro", used))
+	assert.Equal(t, "intro-2", uniqueID("intro", used))
+	assert.Equal(t, "setup", uniqueID("setup", used))
+
+	// A suffixed ID taken by an earlier heading is skipped
+	used = map[string]bool{"faq-1": true}
+	assert.Equal(t, "faq", uniqueID("faq", used))
+	assert.Equal(t, "faq-2", uniqueID("faq", used))
+	assert.True(t, used["faq-2"])
+}
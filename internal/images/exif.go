@@ -0,0 +1,132 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// EXIF holds the metadata read from a JPEG's EXIF block that the pipeline
+// uses. Everything else is dropped when images are re-encoded.
+type EXIF struct {
+	Orientation int
+	DateTime    time.Time
+}
+
+const (
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+)
+
+// ReadEXIF extracts the orientation and capture date from JPEG data. It
+// reports false when the data has no readable EXIF block.
+func ReadEXIF(data []byte) (EXIF, bool) {
+	tiff := findEXIF(data)
+	if len(tiff) < 8 {
+		return EXIF{}, false
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return EXIF{}, false
+	}
+
+	var exif EXIF
+	var dateTime, dateTimeOriginal string
+	var exifIFD uint32
+	readIFD(tiff, order, order.Uint32(tiff[4:]), func(tag, typ uint16, count, value uint32, raw []byte) {
+		switch tag {
+		case tagOrientation:
+			exif.Orientation = int(order.Uint16(raw))
+		case tagDateTime:
+			dateTime = readString(tiff, typ, count, value, raw)
+		case tagExifIFD:
+			exifIFD = value
+		}
+	})
+	if exifIFD != 0 {
+		readIFD(tiff, order, exifIFD, func(tag, typ uint16, count, value uint32, raw []byte) {
+			if tag == tagDateTimeOriginal {
+				dateTimeOriginal = readString(tiff, typ, count, value, raw)
+			}
+		})
+	}
+
+	for _, s := range []string{dateTimeOriginal, dateTime} {
+		if t, err := time.Parse("2006:01:02 15:04:05", s); err == nil {
+			exif.DateTime = t
+			break
+		}
+	}
+	return exif, true
+}
+
+// findEXIF returns the TIFF structure inside a JPEG's APP1 EXIF segment
+func findEXIF(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		// Start of scan: no more metadata segments follow
+		if marker == 0xDA {
+			return nil
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + size
+		if size < 2 || end > len(data) {
+			return nil
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		i = end
+	}
+	return nil
+}
+
+// readIFD calls fn for every entry of the image file directory at offset.
+// raw holds the entry's 4 byte value field.
+func readIFD(tiff []byte, order binary.ByteOrder, offset uint32, fn func(tag, typ uint16, count, value uint32, raw []byte)) {
+	if int(offset)+2 > len(tiff) {
+		return
+	}
+	n := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < n; i++ {
+		entry := int(offset) + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		e := tiff[entry : entry+12]
+		fn(order.Uint16(e), order.Uint16(e[2:]), order.Uint32(e[4:]), order.Uint32(e[8:]), e[8:12])
+	}
+}
+
+// readString returns an ASCII value, stored inline when it fits in four bytes
+func readString(tiff []byte, typ uint16, count, offset uint32, raw []byte) string {
+	const typeASCII = 2
+	if typ != typeASCII || count == 0 {
+		return ""
+	}
+	var b []byte
+	if count <= 4 {
+		b = raw[:count]
+	} else {
+		if uint64(offset)+uint64(count) > uint64(len(tiff)) {
+			return ""
+		}
+		b = tiff[offset : offset+count]
+	}
+	return string(bytes.TrimRight(b, "\x00"))
+}
@@ -0,0 +1,437 @@
+// Package images resizes the site's JPEG and PNG images at build time. Each
+// image is re-encoded without its metadata, scaled to a set of widths for
+// srcset and cached between builds by content hash.
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+	"golang.org/x/image/draw"
+)
+
+// cacheVersion is part of every cache key and must change whenever the
+// output for the same input and options would change
+const cacheVersion = "1"
+
+// ResizedDir is the directory, relative to the output directory, holding the
+// resized variants of images. Keeping them apart from the originals means a
+// variant can never overwrite a source file. Source files inside it are
+// rejected.
+const ResizedDir = "_resized"
+
+// errUndecodable is returned for images that are corrupt or in an
+// unsupported format
+var errUndecodable = errors.New("corrupt or unsupported image")
+
+// Options configures a Processor
+type Options struct {
+	// SourceDir holds the original images, e.g. content/images
+	SourceDir string
+	// OutputDir receives the processed images, e.g. public/images
+	OutputDir string
+	// URLPrefix is the URL path of OutputDir, e.g. /images/
+	URLPrefix string
+	// Widths are the widths generated for srcset
+	Widths []int
+	// MaxWidth limits the width of the image served at the original path.
+	// Zero keeps the original size.
+	MaxWidth int
+	// Quality is the JPEG quality
+	Quality int
+	// Sizes is the sizes attribute used with srcset
+	Sizes string
+	// CacheDir holds processed images between builds. Empty disables caching.
+	CacheDir string
+	// Skip reports whether a file, given by its slash separated path
+	// relative to SourceDir, is left out of the output. Nil keeps all files.
+	Skip func(rel string) bool
+}
+
+// Variant is one size of an image
+type Variant struct {
+	URL    string
+	Width  int
+	Height int
+}
+
+// Image is a processed image and its resized variants, smallest first
+type Image struct {
+	Variant
+	Variants []Variant
+	EXIF     EXIF
+}
+
+// SrcSet returns the value of the srcset attribute for the image
+func (img Image) SrcSet() string {
+	parts := make([]string, 0, len(img.Variants)+1)
+	for _, v := range img.Variants {
+		parts = append(parts, v.URL+" "+strconv.Itoa(v.Width)+"w")
+	}
+	parts = append(parts, img.URL+" "+strconv.Itoa(img.Width)+"w")
+	return strings.Join(parts, ", ")
+}
+
+// Processor processes the images in a directory and remembers the results
+// so that templates and the Markdown renderer can look them up
+type Processor struct {
+	opts Options
+
+	mu     sync.Mutex
+	images map[string]*source
+}
+
+// source is an original image and what is known about it
+type source struct {
+	mu      sync.Mutex
+	rel     string
+	ext     string
+	key     string
+	data    []byte
+	decoded image.Image
+	// scaled is the last variant rendered. Smaller variants are scaled from
+	// it rather than from the original, which is much faster.
+	scaled image.Image
+	img    Image
+}
+
+// cacheInfo is stored with the cached files of an image
+type cacheInfo struct {
+	Width  int  `json:"width"`
+	Height int  `json:"height"`
+	EXIF   EXIF `json:"exif"`
+}
+
+// NewProcessor creates a processor with the given options
+func NewProcessor(opts Options) *Processor {
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = jpeg.DefaultQuality
+	}
+	if !strings.HasSuffix(opts.URLPrefix, "/") {
+		opts.URLPrefix += "/"
+	}
+	return &Processor{opts: opts, images: make(map[string]*source)}
+}
+
+// Process writes every file in the source directory to the output directory.
+// JPEG and PNG images are re-encoded and resized; other files are copied.
+func (p *Processor) Process() error {
+	var files []string
+	err := filepath.WalkDir(p.opts.SourceDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(p.opts.SourceDir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if p.opts.Skip != nil && p.opts.Skip(rel) {
+			return nil
+		}
+		if strings.HasPrefix(rel, ResizedDir+"/") {
+			return fmt.Errorf("%s is reserved for resized images, move %s", filepath.Join(p.opts.SourceDir, ResizedDir), file)
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error listing images: %v", err)
+	}
+
+	// Images are independent, so process them in parallel
+	jobs := make(chan string)
+	errs := make(chan error, len(files))
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				errs <- p.processFile(file)
+			}
+		}()
+	}
+	for _, file := range files {
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Processor) processFile(file string) error {
+	rel, err := filepath.Rel(p.opts.SourceDir, file)
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+	dst := filepath.Join(p.opts.OutputDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(dst), err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("error reading image %s: %v", file, err)
+	}
+	ext := strings.ToLower(path.Ext(rel))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return os.WriteFile(dst, data, 0644)
+	}
+
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\x00%d\x00%d\x00", cacheVersion, p.opts.Quality, p.opts.MaxWidth)
+	sum.Write(data)
+	src := &source{
+		rel:  rel,
+		ext:  ext,
+		key:  hex.EncodeToString(sum.Sum(nil))[:20],
+		data: data,
+	}
+
+	// The original path serves the full size image, limited to MaxWidth
+	orig, err := p.variant(src, p.opts.MaxWidth, dst)
+	if errors.Is(err, errUndecodable) {
+		// Serve the file as it is rather than failing the whole build
+		utils.GetLogger().Warn("image cannot be processed, copying it unchanged", zap.String("path", file), zap.Error(err))
+		return os.WriteFile(dst, data, 0644)
+	}
+	if err != nil {
+		return err
+	}
+	src.img.Variant = orig
+
+	widths := append([]int(nil), p.opts.Widths...)
+	sort.Sort(sort.Reverse(sort.IntSlice(widths)))
+	for _, w := range widths {
+		if w >= orig.Width {
+			continue
+		}
+		v, err := p.variant(src, w, p.variantPath(rel, w))
+		if err != nil {
+			return err
+		}
+		src.img.Variants = append(src.img.Variants, v)
+	}
+	sort.Slice(src.img.Variants, func(i, j int) bool {
+		return src.img.Variants[i].Width < src.img.Variants[j].Width
+	})
+
+	// The source bytes and pixels are only needed again by Resize
+	src.data, src.decoded, src.scaled = nil, nil, nil
+
+	p.mu.Lock()
+	p.images[rel] = src
+	p.mu.Unlock()
+	return nil
+}
+
+// Sizes returns the sizes attribute to use with an image's srcset
+func (p *Processor) Sizes() string {
+	return p.opts.Sizes
+}
+
+// Lookup returns the processed image for a URL or path such as
+// /images/photo.jpg, images/photo.jpg or ../images/photo.jpg
+func (p *Processor) Lookup(src string) (Image, bool) {
+	s, ok := p.source(src)
+	if !ok {
+		return Image{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.img, true
+}
+
+// Resize returns a variant of an image scaled to width, creating it if
+// needed. Images that are not processed by the pipeline, such as external
+// URLs, are returned unchanged with a zero size.
+func (p *Processor) Resize(src string, width int) (Variant, error) {
+	s, ok := p.source(src)
+	if !ok {
+		return Variant{URL: src}, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if width <= 0 || width >= s.img.Width {
+		return s.img.Variant, nil
+	}
+	for _, v := range s.img.Variants {
+		if v.Width == width {
+			return v, nil
+		}
+	}
+
+	v, err := p.variant(s, width, p.variantPath(s.rel, width))
+	if err != nil {
+		return Variant{}, err
+	}
+	s.img.Variants = append(s.img.Variants, v)
+	sort.Slice(s.img.Variants, func(i, j int) bool {
+		return s.img.Variants[i].Width < s.img.Variants[j].Width
+	})
+	return v, nil
+}
+
+// source finds the image referred to by a URL or content path
+func (p *Processor) source(src string) (*source, bool) {
+	if p == nil {
+		return nil, false
+	}
+	rel := strings.TrimPrefix(src, "./")
+	for strings.HasPrefix(rel, "../") {
+		rel = strings.TrimPrefix(rel, "../")
+	}
+	rel = "/" + strings.TrimPrefix(rel, "/")
+	prefix := "/" + strings.Trim(p.opts.URLPrefix, "/") + "/"
+	if !strings.HasPrefix(rel, prefix) {
+		return nil, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.images[strings.TrimPrefix(rel, prefix)]
+	return s, ok
+}
+
+// variantPath returns the output path of an image resized to width, under
+// ResizedDir
+func (p *Processor) variantPath(rel string, width int) string {
+	ext := path.Ext(rel)
+	name := strings.TrimSuffix(rel, ext) + "-" + strconv.Itoa(width) + "w" + ext
+	return filepath.Join(p.opts.OutputDir, ResizedDir, filepath.FromSlash(name))
+}
+
+// variant writes the image scaled down to width, or at its original size
+// when width is zero or larger, to dst. The result comes from the cache when
+// possible. It must be called with s.mu held or before s is shared.
+func (p *Processor) variant(s *source, width int, dst string) (Variant, error) {
+	rel, err := filepath.Rel(p.opts.OutputDir, dst)
+	if err != nil {
+		return Variant{}, err
+	}
+	url := p.opts.URLPrefix + filepath.ToSlash(rel)
+	name := "orig" + s.ext
+	if width > 0 {
+		name = strconv.Itoa(width) + "w" + s.ext
+	}
+	cacheDir := filepath.Join(p.opts.CacheDir, s.key[:2], s.key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return Variant{}, fmt.Errorf("failed to create directory %s: %v", filepath.Dir(dst), err)
+	}
+
+	// Use the cached image when its info and data are both present
+	if p.opts.CacheDir != "" {
+		var info cacheInfo
+		if data, err := os.ReadFile(filepath.Join(cacheDir, name+".json")); err == nil && json.Unmarshal(data, &info) == nil {
+			if data, err := os.ReadFile(filepath.Join(cacheDir, name)); err == nil {
+				if err := os.WriteFile(dst, data, 0644); err != nil {
+					return Variant{}, fmt.Errorf("error writing image %s: %v", dst, err)
+				}
+				s.img.EXIF = info.EXIF
+				return Variant{URL: url, Width: info.Width, Height: info.Height}, nil
+			}
+		}
+	}
+
+	img, err := s.decode(p.opts.SourceDir)
+	if err != nil {
+		return Variant{}, err
+	}
+	if b := img.Bounds(); width > 0 && width < b.Dx() {
+		// Keep the aspect ratio of the original, not of a rounded variant
+		h := b.Dy() * width / b.Dx()
+		from := img
+		if s.scaled != nil && s.scaled.Bounds().Dx() >= width {
+			from = s.scaled
+		}
+		scaled := image.NewRGBA(image.Rect(0, 0, width, max(h, 1)))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), from, from.Bounds(), draw.Src, nil)
+		img = scaled
+	}
+	s.scaled = img
+
+	var buf bytes.Buffer
+	if s.ext == ".png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.opts.Quality})
+	}
+	if err != nil {
+		return Variant{}, fmt.Errorf("error encoding image %s: %v", dst, err)
+	}
+	if err := os.WriteFile(dst, buf.Bytes(), 0644); err != nil {
+		return Variant{}, fmt.Errorf("error writing image %s: %v", dst, err)
+	}
+
+	b := img.Bounds()
+	v := Variant{URL: url, Width: b.Dx(), Height: b.Dy()}
+	if p.opts.CacheDir != "" {
+		info, _ := json.Marshal(cacheInfo{Width: v.Width, Height: v.Height, EXIF: s.img.EXIF})
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return Variant{}, fmt.Errorf("failed to create cache directory: %v", err)
+		}
+		// Write the data before the info so an interrupted build never
+		// leaves info pointing at a partial file
+		if err := os.WriteFile(filepath.Join(cacheDir, name), buf.Bytes(), 0644); err != nil {
+			return Variant{}, fmt.Errorf("error writing image cache: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(cacheDir, name+".json"), info, 0644); err != nil {
+			return Variant{}, fmt.Errorf("error writing image cache: %v", err)
+		}
+	}
+	return v, nil
+}
+
+// decode decodes the original image, applying its EXIF orientation
+func (s *source) decode(sourceDir string) (image.Image, error) {
+	if s.decoded != nil {
+		return s.decoded, nil
+	}
+	data := s.data
+	if data == nil {
+		var err error
+		data, err = os.ReadFile(filepath.Join(sourceDir, filepath.FromSlash(s.rel)))
+		if err != nil {
+			return nil, fmt.Errorf("error reading image %s: %v", s.rel, err)
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image %s: %w: %v", s.rel, errUndecodable, err)
+	}
+	if exif, ok := ReadEXIF(data); ok {
+		s.img.EXIF = exif
+		img = orient(img, exif.Orientation)
+	}
+	s.decoded = img
+	return img, nil
+}
@@ -0,0 +1,230 @@
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// withEXIF inserts an EXIF segment with an orientation and date after the
+// JPEG's start of image marker
+func withEXIF(t *testing.T, data []byte, orientation int, date string) []byte {
+	t.Helper()
+	order := binary.LittleEndian
+	tiff := []byte("II*\x00")
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 2)
+	// Orientation, SHORT inline
+	tiff = order.AppendUint16(tiff, tagOrientation)
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, uint16(orientation))
+	tiff = order.AppendUint16(tiff, 0)
+	// DateTime, ASCII stored after the directory
+	tiff = order.AppendUint16(tiff, tagDateTime)
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = order.AppendUint32(tiff, uint32(len(date)+1))
+	tiff = order.AppendUint32(tiff, 8+2+2*12+4)
+	tiff = order.AppendUint32(tiff, 0)
+	tiff = append(tiff, date+"\x00"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte(nil), data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0x80, 0xFF})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestReadEXIF(t *testing.T) {
+	data := withEXIF(t, encodeJPEG(t, testImage(8, 4)), 6, "2024:05:06 07:08:09")
+
+	exif, ok := ReadEXIF(data)
+	assert.True(t, ok)
+	assert.Equal(t, 6, exif.Orientation)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), exif.DateTime)
+
+	_, ok = ReadEXIF(encodeJPEG(t, testImage(8, 4)))
+	assert.False(t, ok)
+	_, ok = ReadEXIF([]byte("not an image"))
+	assert.False(t, ok)
+}
+
+func TestOrient(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	img.Set(0, 0, red)
+
+	tests := []struct {
+		orientation int
+		size        image.Point
+		red         image.Point
+	}{
+		{1, image.Pt(3, 2), image.Pt(0, 0)},
+		{2, image.Pt(3, 2), image.Pt(2, 0)},
+		{3, image.Pt(3, 2), image.Pt(2, 1)},
+		{6, image.Pt(2, 3), image.Pt(1, 0)},
+		{8, image.Pt(2, 3), image.Pt(0, 2)},
+	}
+	for _, tt := range tests {
+		got := orient(img, tt.orientation)
+		assert.Equal(t, tt.size, got.Bounds().Size(), "orientation %d", tt.orientation)
+		assert.Equal(t, color.Color(red), color.RGBAModel.Convert(got.At(tt.red.X, tt.red.Y)), "orientation %d", tt.orientation)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	dir := t.TempDir()
+	srcDir := filepath.Join(dir, "content", "images")
+	outDir := filepath.Join(dir, "public", "images")
+	assert.NoError(t, os.MkdirAll(filepath.Join(srcDir, "trips"), 0755))
+
+	photo := withEXIF(t, encodeJPEG(t, testImage(200, 100)), 6, "2024:05:06 07:08:09")
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "trips", "photo.jpg"), photo, 0644))
+	var diagram bytes.Buffer
+	assert.NoError(t, png.Encode(&diagram, testImage(60, 30)))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "diagram.png"), diagram.Bytes(), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "notes.txt"), []byte("notes"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "trips", "photo.yaml"), []byte("caption: x"), 0644))
+
+	opts := Options{
+		SourceDir: srcDir,
+		OutputDir: outDir,
+		URLPrefix: "/images/",
+		Widths:    []int{40, 80, 400},
+		MaxWidth:  90,
+		Quality:   80,
+		CacheDir:  filepath.Join(dir, "cache"),
+		Skip:      func(rel string) bool { return rel == "trips/photo.yaml" },
+	}
+	p := NewProcessor(opts)
+	assert.NoError(t, p.Process())
+
+	// The photo is rotated upright, limited to MaxWidth and has its EXIF
+	// block stripped
+	img, ok := p.Lookup("../images/trips/photo.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, Variant{URL: "/images/trips/photo.jpg", Width: 90, Height: 180}, img.Variant)
+	assert.Equal(t, []Variant{
+		{URL: "/images/_resized/trips/photo-40w.jpg", Width: 40, Height: 80},
+		{URL: "/images/_resized/trips/photo-80w.jpg", Width: 80, Height: 160},
+	}, img.Variants)
+	assert.Equal(t, 6, img.EXIF.Orientation)
+	assert.Equal(t, "/images/_resized/trips/photo-40w.jpg 40w, /images/_resized/trips/photo-80w.jpg 80w, /images/trips/photo.jpg 90w", img.SrcSet())
+
+	data, err := os.ReadFile(filepath.Join(outDir, "trips", "photo.jpg"))
+	assert.NoError(t, err)
+	_, ok = ReadEXIF(data)
+	assert.False(t, ok)
+	assert.FileExists(t, filepath.Join(outDir, ResizedDir, "trips", "photo-40w.jpg"))
+
+	// Small images keep their size and non-images are copied
+	img, ok = p.Lookup("/images/diagram.png")
+	assert.True(t, ok)
+	assert.Equal(t, 60, img.Width)
+	assert.Equal(t, []Variant{{URL: "/images/_resized/diagram-40w.png", Width: 40, Height: 20}}, img.Variants)
+	notes, err := os.ReadFile(filepath.Join(outDir, "notes.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "notes", string(notes))
+	_, ok = p.Lookup("/images/notes.txt")
+	assert.False(t, ok)
+	assert.NoFileExists(t, filepath.Join(outDir, "trips", "photo.yaml"))
+	_, ok = p.Lookup("https://example.com/images/diagram.png")
+	assert.False(t, ok)
+
+	// Widths not in the configuration are created on demand
+	v, err := p.Resize("/images/trips/photo.jpg", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, Variant{URL: "/images/_resized/trips/photo-30w.jpg", Width: 30, Height: 60}, v)
+	assert.FileExists(t, filepath.Join(outDir, ResizedDir, "trips", "photo-30w.jpg"))
+	v, err = p.Resize("https://example.com/a.jpg", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, Variant{URL: "https://example.com/a.jpg"}, v)
+
+	// A second build is served from the cache, including the metadata
+	assert.NoError(t, os.RemoveAll(outDir))
+	p = NewProcessor(opts)
+	assert.NoError(t, p.Process())
+	cached, ok := p.Lookup("/images/trips/photo.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, 6, cached.EXIF.Orientation)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), cached.EXIF.DateTime)
+	assert.Equal(t, 90, cached.Width)
+	out, err := os.ReadFile(filepath.Join(outDir, "trips", "photo.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, data, out)
+}
+
+func TestProcessCorruptImage(t *testing.T) {
+	// Init logger
+	utils.InitLogger(&config.Config{})
+
+	dir := t.TempDir()
+	srcDir := filepath.Join(dir, "content", "images")
+	outDir := filepath.Join(dir, "public", "images")
+	assert.NoError(t, os.MkdirAll(srcDir, 0755))
+
+	photo := encodeJPEG(t, testImage(200, 100))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "photo.jpg"), photo, 0644))
+	// A source file named like a variant of photo.jpg
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "photo-80w.jpg"), photo, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, "broken.png"), []byte("not a png"), 0644))
+
+	p := NewProcessor(Options{SourceDir: srcDir, OutputDir: outDir, URLPrefix: "/images/", Widths: []int{80}})
+	assert.NoError(t, p.Process())
+
+	// The broken image is copied unchanged and left out of lookups
+	data, err := os.ReadFile(filepath.Join(outDir, "broken.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "not a png", string(data))
+	_, ok := p.Lookup("/images/broken.png")
+	assert.False(t, ok)
+
+	// The variant does not replace the source file of the same name
+	img, ok := p.Lookup("/images/photo-80w.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, 200, img.Width)
+	img, ok = p.Lookup("/images/photo.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, []Variant{{URL: "/images/_resized/photo-80w.jpg", Width: 80, Height: 40}}, img.Variants)
+}
+
+func TestProcessReservedDir(t *testing.T) {
+	dir := t.TempDir()
+	srcDir := filepath.Join(dir, "content", "images")
+	assert.NoError(t, os.MkdirAll(filepath.Join(srcDir, ResizedDir), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(srcDir, ResizedDir, "photo.jpg"), encodeJPEG(t, testImage(20, 10)), 0644))
+
+	p := NewProcessor(Options{SourceDir: srcDir, OutputDir: filepath.Join(dir, "public", "images"), URLPrefix: "/images/"})
+	assert.Error(t, p.Process())
+}
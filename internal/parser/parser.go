@@ -56,6 +56,10 @@ func ParsePost(filePath string) (post.Post, error) {
 	if err != nil {
 		return post.Post{}, err
 	}
+	var params map[string]interface{}
+	if err := yaml.Unmarshal([]byte(parts[1]), &params); err != nil {
+		return post.Post{}, err
+	}
 
 	// Parse the date string into a time.Time object, try multiple formats
 	date, err := time.Parse("January 2, 2006 15:04", meta.Date)
@@ -71,17 +75,38 @@ func ParsePost(filePath string) (post.Post, error) {
 
 	// Create the post
 	p := post.Post{
-		Title:       meta.Title,
-		Description: meta.Description,
-		Date:        date,
-		Tags:        meta.Tags,
-		Content:     parts[2],
-		Slug:        filepath.Base(filepath.Dir(filePath)),
+		Title:           meta.Title,
+		Description:     meta.Description,
+		Date:            date,
+		Tags:            meta.Tags,
+		Content:         parts[2],
+		Slug:            filepath.Base(filepath.Dir(filePath)),
+		FeaturedImage:   meta.FeaturedImage,
+		DisableComments: meta.Comments != nil && !*meta.Comments,
+		TOC:             meta.TOC,
+		Series:          strings.TrimSpace(meta.Series),
+		Authors:         postAuthors(meta),
+		Pinned:          meta.Pinned,
+		Featured:        meta.Featured,
+		Weight:          meta.Weight,
+		Params:          params,
 	}
 
 	return p, nil
 }
 
+// postAuthors returns the authors a post names under its author and authors
+// keys, in that order
+func postAuthors(meta post.PostMeta) []string {
+	var authors []string
+	for _, name := range append([]string{meta.Author}, meta.Authors...) {
+		if name = strings.TrimSpace(name); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	return authors
+}
+
 type Page struct {
 	Title         string
 	Date          time.Time
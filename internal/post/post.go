@@ -1,21 +1,69 @@
 package post
 
 import (
+	"html/template"
 	"time"
 )
 
 type Post struct {
-	Title       string
-	Description string
-	Date        time.Time
-	Tags        []string
-	Content     string
-	Slug        string
+	Title           string
+	Description     string
+	Date            time.Time
+	Tags            []string
+	Content         string
+	Slug            string
+	FeaturedImage   string
+	DisableComments bool
+	// TOC turns the table of contents on or off for the post. Nil uses the
+	// site's setting.
+	TOC *bool
+	// Params holds the whole front matter, including keys such as
+	// taxonomies that have no field of their own
+	Params map[string]interface{}
+	// Series is the name of the series the post is part of, if any
+	Series string
+	// Pinned posts are listed first on the index, and Featured posts shown
+	// apart on it. Weight orders either, lower weights first.
+	Pinned   bool
+	Featured bool
+	Weight   int
+	// Authors are the names of the post's authors, from its author and
+	// authors front matter. The generator replaces them with the names from
+	// the authors data file and gives posts naming none the site's author.
+	Authors []string
+	// AuthorIDs are the IDs from the authors data file of the authors, in
+	// the order of Authors, empty for authors missing from it. They are set
+	// by the generator, as authors with the same name may be different
+	// people.
+	AuthorIDs []string
+	// HTML is the rendered content and Text its readable text without code
+	// blocks. The generator renders each post once and sets them for the
+	// pages, summaries, feeds and search index built from it.
+	HTML template.HTML
+	Text string
+	// Summary is the HTML of the content up to the <!--more--> separator,
+	// or of its first words. It is set by the generator.
+	Summary template.HTML
+	// Truncated is set when the summary leaves out part of the content
+	Truncated bool
+	// WordCount and ReadingTime, in minutes, are measured on the rendered
+	// text without code blocks. They are set by the generator.
+	WordCount   int
+	ReadingTime int
 }
 
 type PostMeta struct {
-	Title       string   `yaml:"title"`
-	Description string   `yaml:"description"`
-	Date        string   `yaml:"date"`
-	Tags        []string `yaml:"tags"`
+	Title         string   `yaml:"title"`
+	Description   string   `yaml:"description"`
+	Date          string   `yaml:"date"`
+	Tags          []string `yaml:"tags"`
+	FeaturedImage string   `yaml:"featured_image"`
+	Comments      *bool    `yaml:"comments"`
+	TOC           *bool    `yaml:"toc"`
+	Series        string   `yaml:"series"`
+	Author        string   `yaml:"author"`
+	Authors       []string `yaml:"authors"`
+	Pinned        bool     `yaml:"pinned"`
+	Featured      bool     `yaml:"featured"`
+	Weight        int      `yaml:"weight"`
 }
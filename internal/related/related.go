@@ -0,0 +1,198 @@
+// Package related finds the posts most related to each post. Pairs of
+// posts are scored by the cosine similarity of their tags and of the TF-IDF
+// vectors of their titles and bodies, each scaled by a configurable weight.
+//
+// Scores are accumulated through inverted indexes rather than by comparing
+// every pair of posts. Each body keeps only its strongest terms and each
+// posting list only its strongest posts, so the work grows linearly with
+// the number of posts.
+package related
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/search"
+)
+
+// maxBodyTerms is the number of terms with the highest TF-IDF weight kept
+// for a post's body
+const maxBodyTerms = 25
+
+// maxPostings is the number of posts with the highest weight kept for a
+// term. Terms shared by more posts say little about how related they are.
+const maxPostings = 200
+
+// Weights scale the similarity of tags, titles and bodies in the score of
+// a pair of posts. A weight of zero ignores that field.
+type Weights struct {
+	Tags    float64
+	Title   float64
+	Content float64
+}
+
+// Options configure Find
+type Options struct {
+	// Count is the maximum number of related posts returned for a post
+	Count   int
+	Weights Weights
+}
+
+// entry is a term's weight in a post's vector, or a post's weight in a
+// term's posting list
+type entry struct {
+	id     int
+	weight float64
+}
+
+// field holds the normalized vectors of one field of every post and the
+// inverted index over them
+type field struct {
+	weight   float64
+	vectors  [][]entry
+	postings [][]entry
+}
+
+// Find returns, for each post, the indexes of up to opts.Count other posts
+// ordered from the most related. Posts sharing nothing are never related.
+// Ties keep the order of posts.
+func Find(posts []post.Post, opts Options) [][]int {
+	related := make([][]int, len(posts))
+	if opts.Count <= 0 || len(posts) < 2 {
+		return related
+	}
+
+	tags := make([][]string, len(posts))
+	titles := make([][]string, len(posts))
+	bodies := make([][]string, len(posts))
+	for i, p := range posts {
+		for _, tag := range p.Tags {
+			tags[i] = append(tags[i], strings.ToLower(tag))
+		}
+		titles[i] = search.Tokenize(p.Title)
+		bodies[i] = search.Tokenize(p.Content)
+	}
+
+	var fields []field
+	for _, f := range []struct {
+		docs     [][]string
+		weight   float64
+		maxTerms int
+	}{
+		{tags, opts.Weights.Tags, 0},
+		{titles, opts.Weights.Title, 0},
+		{bodies, opts.Weights.Content, maxBodyTerms},
+	} {
+		if f.weight > 0 {
+			fields = append(fields, newField(f.docs, f.weight, f.maxTerms))
+		}
+	}
+
+	scores := make([]float64, len(posts))
+	var touched []int
+	for i := range posts {
+		for _, f := range fields {
+			for _, t := range f.vectors[i] {
+				for _, p := range f.postings[t.id] {
+					if p.id == i {
+						continue
+					}
+					if scores[p.id] == 0 {
+						touched = append(touched, p.id)
+					}
+					scores[p.id] += f.weight * t.weight * p.weight
+				}
+			}
+		}
+
+		sort.Slice(touched, func(a, b int) bool {
+			if scores[touched[a]] != scores[touched[b]] {
+				return scores[touched[a]] > scores[touched[b]]
+			}
+			return touched[a] < touched[b]
+		})
+		n := min(len(touched), opts.Count)
+		related[i] = append([]int(nil), touched[:n]...)
+
+		for _, j := range touched {
+			scores[j] = 0
+		}
+		touched = touched[:0]
+	}
+	return related
+}
+
+// newField builds the TF-IDF vectors of a field from the terms of every
+// post, keeping the maxTerms strongest terms of each when maxTerms is
+// positive. Terms found in every post have no weight.
+func newField(docs [][]string, weight float64, maxTerms int) field {
+	ids := make(map[string]int)
+	var df []int
+	freqs := make([]map[int]float64, len(docs))
+	for i, terms := range docs {
+		tf := make(map[int]float64)
+		for _, term := range terms {
+			id, ok := ids[term]
+			if !ok {
+				id = len(df)
+				ids[term] = id
+				df = append(df, 0)
+			}
+			if tf[id] == 0 {
+				df[id]++
+			}
+			tf[id]++
+		}
+		freqs[i] = tf
+	}
+
+	f := field{
+		weight:   weight,
+		vectors:  make([][]entry, len(docs)),
+		postings: make([][]entry, len(df)),
+	}
+	n := float64(len(docs))
+	for i, tf := range freqs {
+		vec := make([]entry, 0, len(tf))
+		for id, freq := range tf {
+			if w := (1 + math.Log(freq)) * math.Log(n/float64(df[id])); w > 0 {
+				vec = append(vec, entry{id, w})
+			}
+		}
+		sortEntries(vec)
+		if maxTerms > 0 && len(vec) > maxTerms {
+			vec = vec[:maxTerms]
+		}
+
+		var norm float64
+		for _, e := range vec {
+			norm += e.weight * e.weight
+		}
+		norm = math.Sqrt(norm)
+		for j := range vec {
+			vec[j].weight /= norm
+			f.postings[vec[j].id] = append(f.postings[vec[j].id], entry{i, vec[j].weight})
+		}
+		f.vectors[i] = vec
+	}
+
+	for id, list := range f.postings {
+		if len(list) > maxPostings {
+			sortEntries(list)
+			f.postings[id] = list[:maxPostings]
+		}
+	}
+	return f
+}
+
+// sortEntries orders entries by decreasing weight, then by id
+func sortEntries(entries []entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].weight != entries[j].weight {
+			return entries[i].weight > entries[j].weight
+		}
+		return entries[i].id < entries[j].id
+	})
+}
@@ -0,0 +1,95 @@
+package related
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/stretchr/testify/assert"
+)
+
+var testWeights = Weights{Tags: 1, Title: 0.5, Content: 1}
+
+func TestFind(t *testing.T) {
+	posts := []post.Post{
+		{Title: "Learning Go", Tags: []string{"go", "programming"}, Content: "Goroutines and channels make concurrency simple."},
+		{Title: "Baking sourdough bread", Tags: []string{"baking"}, Content: "Flour, water, salt and a lively starter."},
+		{Title: "Concurrency in Go", Tags: []string{"Go"}, Content: "Channels connect goroutines."},
+		{Title: "Rust for Go programmers", Tags: []string{"rust", "programming"}, Content: "Ownership instead of a garbage collector."},
+		{Title: "Rye bread", Tags: []string{"baking"}, Content: "A dense loaf from rye flour and starter."},
+	}
+
+	related := Find(posts, Options{Count: 2, Weights: testWeights})
+	assert.Len(t, related, len(posts))
+	assert.Equal(t, []int{2, 3}, related[0])
+	assert.Equal(t, []int{4}, related[1])
+	assert.Equal(t, []int{0, 3}, related[2])
+	assert.Equal(t, []int{1}, related[4])
+
+	// Only the bodies are compared without tag and title weights
+	related = Find(posts, Options{Count: 2, Weights: Weights{Content: 1}})
+	assert.Equal(t, []int{2}, related[0])
+	assert.Empty(t, related[3])
+}
+
+func TestFindCount(t *testing.T) {
+	posts := []post.Post{
+		{Title: "One", Tags: []string{"a"}},
+		{Title: "Two", Tags: []string{"a"}},
+		{Title: "Three", Tags: []string{"b"}},
+	}
+	assert.Equal(t, [][]int{nil, nil, nil}, Find(posts, Options{Weights: testWeights}))
+	assert.Equal(t, [][]int{{1}, {0}, nil}, Find(posts, Options{Count: 5, Weights: testWeights}))
+	assert.Equal(t, [][]int{nil}, Find(posts[:1], Options{Count: 5, Weights: testWeights}))
+}
+
+// benchmarkPosts returns n posts with titles, tags and bodies drawn from a
+// fixed vocabulary, so that many posts share terms
+func benchmarkPosts(n int) []post.Post {
+	r := rand.New(rand.NewSource(1))
+	vocabulary := make([]string, 5000)
+	for i := range vocabulary {
+		vocabulary[i] = fmt.Sprintf("word%d", i)
+	}
+	// Skew the choice of words towards the start of the vocabulary, as in
+	// natural language
+	word := func() string {
+		return vocabulary[int(float64(len(vocabulary))*r.Float64()*r.Float64())]
+	}
+
+	posts := make([]post.Post, n)
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range posts {
+		title := make([]string, 6)
+		for j := range title {
+			title[j] = word()
+		}
+		body := make([]string, 600)
+		for j := range body {
+			body[j] = word()
+		}
+		posts[i] = post.Post{
+			Title:   strings.Join(title, " "),
+			Date:    date.AddDate(0, 0, -i),
+			Tags:    []string{fmt.Sprintf("tag%d", r.Intn(50)), fmt.Sprintf("tag%d", r.Intn(200))},
+			Content: strings.Join(body, " "),
+			Slug:    date.AddDate(0, 0, -i).Format("2006-01-02"),
+		}
+	}
+	return posts
+}
+
+func benchmarkFind(b *testing.B, n int) {
+	posts := benchmarkPosts(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Find(posts, Options{Count: 5, Weights: testWeights})
+	}
+}
+
+func BenchmarkFind100(b *testing.B)  { benchmarkFind(b, 100) }
+func BenchmarkFind1000(b *testing.B) { benchmarkFind(b, 1000) }
+func BenchmarkFind5000(b *testing.B) { benchmarkFind(b, 5000) }
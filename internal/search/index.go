@@ -0,0 +1,194 @@
+// Package search builds an inverted index over the site's posts. The index is
+// written as sharded JSON for the client-side search UI and can also be
+// queried in memory.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Field weights applied to term frequencies, so that a match in a title
+// counts for more than a match in the body
+const (
+	titleWeight       = 5
+	tagWeight         = 3
+	descriptionWeight = 2
+	bodyWeight        = 1
+)
+
+// maxBodyTerms caps the number of distinct body terms indexed per document,
+// keeping the most frequent ones. This bounds the index size for long posts.
+const maxBodyTerms = 150
+
+// prefixWeight scales the score of terms matched by prefix rather than exactly
+const prefixWeight = 0.5
+
+// Document is a piece of content added to the index
+type Document struct {
+	Title       string
+	Description string
+	URL         string
+	Tags        []string
+	Date        time.Time
+	Body        string
+	WordCount   int
+	ReadingTime int
+}
+
+// Posting records the weight of a term in a document
+type Posting struct {
+	Doc    int
+	Weight float64
+}
+
+// Result is a document matching a query
+type Result struct {
+	Document
+	ID    int
+	Score float64
+}
+
+// Index is an inverted index from terms to the documents containing them
+type Index struct {
+	Docs  []Document
+	terms map[string][]Posting
+	// sorted holds all terms in order for prefix lookups
+	sorted []string
+}
+
+// NewIndex builds an index over docs. Term weights are TF-IDF scores using
+// field-weighted term frequencies.
+func NewIndex(docs []Document) *Index {
+	idx := &Index{
+		Docs:  docs,
+		terms: make(map[string][]Posting),
+	}
+
+	freqs := make([]map[string]float64, len(docs))
+	df := make(map[string]int)
+	for i, d := range docs {
+		tf := make(map[string]float64)
+		add := func(text string, weight float64) {
+			for _, t := range Tokenize(text) {
+				tf[t] += weight
+			}
+		}
+		add(d.Title, titleWeight)
+		for _, tag := range d.Tags {
+			add(tag, tagWeight)
+		}
+		add(d.Description, descriptionWeight)
+
+		body := make(map[string]float64)
+		for _, t := range Tokenize(d.Body) {
+			body[t]++
+		}
+		for _, t := range topTerms(body, maxBodyTerms) {
+			tf[t] += body[t] * bodyWeight
+		}
+
+		freqs[i] = tf
+		for t := range tf {
+			df[t]++
+		}
+	}
+
+	n := float64(len(docs))
+	for i, tf := range freqs {
+		for t, f := range tf {
+			w := (1 + math.Log(f)) * math.Log(1+n/float64(df[t]))
+			idx.terms[t] = append(idx.terms[t], Posting{Doc: i, Weight: math.Round(w*1000) / 1000})
+		}
+	}
+
+	for t := range idx.terms {
+		idx.sorted = append(idx.sorted, t)
+	}
+	sort.Strings(idx.sorted)
+
+	return idx
+}
+
+// topTerms returns up to n terms with the highest frequency
+func topTerms(freq map[string]float64, n int) []string {
+	terms := make([]string, 0, len(freq))
+	for t := range freq {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if freq[terms[i]] != freq[terms[j]] {
+			return freq[terms[i]] > freq[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	return terms[:min(len(terms), n)]
+}
+
+// Search returns up to limit documents containing every term of the query,
+// best match first. The last query term also matches by prefix so that
+// results can be shown while the user is typing.
+func (idx *Index) Search(query string, limit int) []Result {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	matched := make(map[int]int)
+	for i, tok := range tokens {
+		hits := make(map[int]float64)
+		for _, p := range idx.terms[tok] {
+			hits[p.Doc] = p.Weight
+		}
+		if i == len(tokens)-1 {
+			for _, term := range idx.withPrefix(tok) {
+				for _, p := range idx.terms[term] {
+					hits[p.Doc] = math.Max(hits[p.Doc], p.Weight*prefixWeight)
+				}
+			}
+		}
+		for doc, w := range hits {
+			scores[doc] += w
+			matched[doc]++
+		}
+	}
+
+	var results []Result
+	for doc, score := range scores {
+		if matched[doc] == len(tokens) {
+			results = append(results, Result{Document: idx.Docs[doc], ID: doc, Score: score})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].Date.Equal(results[j].Date) {
+			return results[i].Date.After(results[j].Date)
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// withPrefix returns the indexed terms that start with prefix, excluding
+// prefix itself
+func (idx *Index) withPrefix(prefix string) []string {
+	var terms []string
+	for i := sort.SearchStrings(idx.sorted, prefix); i < len(idx.sorted); i++ {
+		if !strings.HasPrefix(idx.sorted[i], prefix) {
+			break
+		}
+		if idx.sorted[i] != prefix {
+			terms = append(terms, idx.sorted[i])
+		}
+	}
+	return terms
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func testDocuments() []Document {
+	return []Document{
+		{
+			Title: "Getting started with Go",
+			URL:   "/posts/getting-started-with-go.html",
+			Tags:  []string{"golang"},
+			Date:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Body:  "Install the toolchain and write your first program.",
+		},
+		{
+			Title: "Baking bread",
+			URL:   "/posts/baking-bread.html",
+			Tags:  []string{"cooking"},
+			Date:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Body:  "A program for the weekend: flour, water, salt and time.",
+		},
+		{
+			Title:       "Testing in Go",
+			Description: "Table driven tests",
+			URL:         "/posts/testing-in-go.html",
+			Tags:        []string{"golang", "testing"},
+			Date:        time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Body:        "The testing package runs every test in a program.",
+		},
+	}
+}
+
+func resultURLs(results []Result) []string {
+	var urls []string
+	for _, r := range results {
+		urls = append(urls, r.URL)
+	}
+	return urls
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := NewIndex(testDocuments())
+
+	// A title match outranks a body-only match
+	results := idx.Search("program", 0)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %v", resultURLs(results))
+	}
+
+	results = idx.Search("testing", 0)
+	if len(results) != 1 || results[0].URL != "/posts/testing-in-go.html" {
+		t.Errorf("unexpected results for testing: %v", resultURLs(results))
+	}
+
+	results = idx.Search("bread", 0)
+	if len(results) != 1 || results[0].URL != "/posts/baking-bread.html" {
+		t.Errorf("unexpected results for bread: %v", resultURLs(results))
+	}
+
+	// Among title matches the newer post wins a tie
+	results = idx.Search("go", 0)
+	if len(results) != 2 || results[0].URL != "/posts/testing-in-go.html" {
+		t.Errorf("unexpected results for go: %v", resultURLs(results))
+	}
+}
+
+func TestSearchFieldWeights(t *testing.T) {
+	idx := NewIndex([]Document{
+		{Title: "Notes", URL: "/body", Body: "kubernetes kubernetes"},
+		{Title: "Kubernetes", URL: "/title", Body: "notes"},
+	})
+
+	results := idx.Search("kubernetes", 0)
+	if len(results) != 2 || results[0].URL != "/title" {
+		t.Errorf("expected title match first, got %v", resultURLs(results))
+	}
+}
+
+func TestSearchRequiresAllTerms(t *testing.T) {
+	idx := NewIndex(testDocuments())
+
+	results := idx.Search("golang program", 0)
+	if len(results) != 2 {
+		t.Errorf("expected 2 results, got %v", resultURLs(results))
+	}
+
+	results = idx.Search("golang bread", 0)
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %v", resultURLs(results))
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	idx := NewIndex(testDocuments())
+
+	results := idx.Search("bre", 0)
+	if len(results) != 1 || results[0].URL != "/posts/baking-bread.html" {
+		t.Errorf("unexpected results for prefix: %v", resultURLs(results))
+	}
+
+	// Only the last term matches by prefix
+	results = idx.Search("bre baking", 0)
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %v", resultURLs(results))
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	idx := NewIndex(testDocuments())
+
+	if results := idx.Search("program", 2); len(results) != 2 {
+		t.Errorf("expected 2 results, got %d", len(results))
+	}
+	if results := idx.Search("the", 0); results != nil {
+		t.Errorf("expected no results for a stop word query, got %v", resultURLs(results))
+	}
+}
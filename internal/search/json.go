@@ -0,0 +1,96 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// jsonDoc is the compact form of a document in docs.json
+type jsonDoc struct {
+	Title       string   `json:"t"`
+	URL         string   `json:"u"`
+	Description string   `json:"d,omitempty"`
+	Tags        []string `json:"g,omitempty"`
+	Date        string   `json:"p"`
+	WordCount   int      `json:"w,omitempty"`
+	ReadingTime int      `json:"r,omitempty"`
+}
+
+// ShardKey returns the shard a term is stored in: its first character for
+// terms starting with a-z or 0-9 and "_" for everything else
+func ShardKey(term string) string {
+	if c := term[0]; (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+		return string(c)
+	}
+	return "_"
+}
+
+// WriteJSON writes the index to dir as docs.json, holding the document list,
+// and one terms-<shard>.json file per shard. Each shard maps a term to a flat
+// array of document number and weight pairs, so the client only downloads
+// the shards for the terms in a query.
+func (idx *Index) WriteJSON(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating search directory: %v", err)
+	}
+
+	// Remove shards from previous builds
+	old, err := filepath.Glob(filepath.Join(dir, "terms-*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range old {
+		if err := os.Remove(f); err != nil {
+			return err
+		}
+	}
+
+	docs := make([]jsonDoc, len(idx.Docs))
+	for i, d := range idx.Docs {
+		docs[i] = jsonDoc{
+			Title:       d.Title,
+			URL:         d.URL,
+			Description: d.Description,
+			Tags:        d.Tags,
+			Date:        d.Date.Format("2006-01-02"),
+			WordCount:   d.WordCount,
+			ReadingTime: d.ReadingTime,
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, "docs.json"), docs); err != nil {
+		return err
+	}
+
+	shards := make(map[string]map[string][]float64)
+	for _, term := range idx.sorted {
+		key := ShardKey(term)
+		if shards[key] == nil {
+			shards[key] = make(map[string][]float64)
+		}
+		postings := make([]float64, 0, 2*len(idx.terms[term]))
+		for _, p := range idx.terms[term] {
+			postings = append(postings, float64(p.Doc), p.Weight)
+		}
+		shards[key][term] = postings
+	}
+	for key, terms := range shards {
+		if err := writeJSON(filepath.Join(dir, "terms-"+key+".json"), terms); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
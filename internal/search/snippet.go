@@ -0,0 +1,61 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Snippet returns an excerpt of about n words of text around the first match
+// of the query, as HTML with the matching words wrapped in <mark>. Like
+// Search, the last query term also matches by prefix.
+func Snippet(text, query string, n int) string {
+	tokens := Tokenize(query)
+	terms := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		terms[t] = true
+	}
+	last := ""
+	if len(tokens) > 0 {
+		last = tokens[len(tokens)-1]
+	}
+
+	words := strings.Fields(text)
+	match := make([]bool, len(words))
+	first := -1
+	for i, w := range words {
+		for _, t := range Tokenize(w) {
+			if terms[t] || (last != "" && strings.HasPrefix(t, last)) {
+				match[i] = true
+			}
+		}
+		if match[i] && first < 0 {
+			first = i
+		}
+	}
+
+	// Show some context before the first match
+	start := 0
+	if first > n/3 {
+		start = first - n/3
+	}
+	end := min(len(words), start+n)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteByte(' ')
+		}
+		if match[i] {
+			b.WriteString("<mark>" + html.EscapeString(words[i]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(words[i]))
+		}
+	}
+	if end < len(words) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package search
+
+import "testing"
+
+func TestSnippet(t *testing.T) {
+	text := "one two three four five six seven eight nine ten Testing <b>eleven</b> twelve"
+
+	tests := []struct {
+		name  string
+		query string
+		n     int
+		want  string
+	}{
+		{
+			name:  "Highlights stemmed matches with context",
+			query: "tests",
+			n:     6,
+			want:  "… nine ten <mark>Testing</mark> &lt;b&gt;eleven&lt;/b&gt; twelve",
+		},
+		{
+			name:  "Highlights prefix matches of the last term",
+			query: "sev",
+			n:     4,
+			want:  "… six <mark>seven</mark> eight nine …",
+		},
+		{
+			name:  "Starts at the beginning without a match",
+			query: "missing",
+			n:     3,
+			want:  "one two three …",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(text, tt.query, tt.n); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
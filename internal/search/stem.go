@@ -0,0 +1,67 @@
+package search
+
+import "strings"
+
+// Stem reduces an English word to a crude stem by stripping common plural and
+// verb suffixes. It is deliberately much simpler than a full Porter stemmer so
+// that search.js can mirror it exactly; both sides only need to agree.
+func Stem(word string) string {
+	if len(word) <= 3 || !isASCII(word) {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = word[:len(word)-1]
+	}
+
+	word = stripSuffix(word)
+
+	// Drop a final "e" so that "release" and "released" share a stem
+	if len(word) >= 4 && strings.HasSuffix(word, "e") {
+		word = word[:len(word)-1]
+	}
+
+	return word
+}
+
+func stripSuffix(word string) string {
+	for _, suffix := range []string{"ing", "ed"} {
+		stem := strings.TrimSuffix(word, suffix)
+		if stem == word || len(stem) < 3 || !hasVowel(stem) {
+			continue
+		}
+		// running -> runn -> run
+		n := len(stem)
+		if stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiouslz", rune(stem[n-1])) {
+			stem = stem[:n-1]
+		}
+		return stem
+	}
+
+	for _, suffix := range []string{"ness", "ment", "ly"} {
+		if stem := strings.TrimSuffix(word, suffix); stem != word && len(stem) >= 3 {
+			return stem
+		}
+	}
+
+	return word
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
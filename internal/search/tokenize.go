@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words that carry no meaning in a query
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true,
+	"have": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "of": true, "on": true, "or": true, "so": true, "that": true,
+	"the": true, "their": true, "then": true, "there": true, "these": true,
+	"this": true, "to": true, "was": true, "were": true, "will": true,
+	"with": true, "you": true, "your": true,
+}
+
+// Tokenize splits text into lower-cased, stemmed terms. Stop words and single
+// letters are dropped. Han, Hiragana, Katakana and Hangul characters are
+// emitted as individual terms since those scripts do not separate words with
+// spaces.
+//
+// The client-side search script (search.js) implements the same rules and
+// must be kept in sync.
+func Tokenize(text string) []string {
+	var terms []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		if len([]rune(w)) < 2 || stopWords[w] {
+			return
+		}
+		terms = append(terms, Stem(w))
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case IsCJK(r):
+			flush()
+			terms = append(terms, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word.WriteRune(r)
+		case r == '\'' || r == '’':
+			// Drop apostrophes so that "don't" becomes "dont"
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return terms
+}
+
+// IsCJK reports whether r is a Chinese, Japanese or Korean character, which
+// is treated as a word of its own when tokenizing text and counting words
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,95 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "Lower-cases and splits on punctuation",
+			text: "Hello, World! Go-Lang",
+			want: []string{"hello", "world", "go", "lang"},
+		},
+		{
+			name: "Drops stop words and single letters",
+			text: "The state of a C program",
+			want: []string{"stat", "program"},
+		},
+		{
+			name: "Stems words",
+			text: "Running tests quickly",
+			want: []string{"run", "test", "quick"},
+		},
+		{
+			name: "Drops apostrophes",
+			text: "Don't panic",
+			want: []string{"dont", "panic"},
+		},
+		{
+			name: "Keeps numbers",
+			text: "Go 1.23 released",
+			want: []string{"go", "23", "releas"},
+		},
+		{
+			name: "Splits CJK characters",
+			text: "静的サイト",
+			want: []string{"静", "的", "サ", "イ", "ト"},
+		},
+		{
+			name: "Keeps accented words",
+			text: "Café crème",
+			want: []string{"café", "crème"},
+		},
+		{
+			name: "Empty text",
+			text: "  ...  ",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"go":         "go",
+		"posts":      "post",
+		"stories":    "story",
+		"classes":    "class",
+		"class":      "class",
+		"status":     "status",
+		"analysis":   "analysis",
+		"running":    "run",
+		"tested":     "test",
+		"falling":    "fall",
+		"sing":       "sing",
+		"red":        "red",
+		"happiness":  "happi",
+		"deployment": "deploy",
+		"quickly":    "quick",
+		"release":    "releas",
+		"released":   "releas",
+		"code":       "cod",
+		"coding":     "cod",
+		"use":        "use",
+		"naïve":      "naïve",
+	}
+
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
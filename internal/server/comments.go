@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/intothevoid/likho/internal/comments"
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/generator"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	maxCommentBytes = 64 << 10
+	maxNameLength   = 100
+	maxBodyLength   = 5000
+)
+
+// commentsAPI accepts comment submissions at <endpoint>/<post> and stores
+// them as pending comments for moderation
+type commentsAPI struct {
+	prefix  string
+	store   *comments.Store
+	limiter *rateLimiter
+	posts   atomic.Pointer[map[string]bool]
+}
+
+type commentRequest struct {
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	Website string `json:"website"`
+}
+
+type commentResponse struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+}
+
+func newCommentsAPI(cfg *config.Config) *commentsAPI {
+	return &commentsAPI{
+		prefix:  strings.TrimRight(cfg.Comments.SelfHosted.Endpoint, "/") + "/",
+		store:   comments.NewStore(comments.Dir(cfg)),
+		limiter: newRateLimiter(cfg.Comments.SelfHosted.RateLimit, cfg.Comments.SelfHosted.RateWindow),
+	}
+}
+
+// update replaces the set of posts that accept comments
+func (c *commentsAPI) update(posts []post.Post) {
+	ids := make(map[string]bool, len(posts))
+	for _, p := range posts {
+		if !p.DisableComments {
+			ids[generator.PostID(p)] = true
+		}
+	}
+	c.posts.Store(&ids)
+}
+
+func (c *commentsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, c.prefix)
+	posts := c.posts.Load()
+	if posts == nil || !(*posts)[id] {
+		writeJSONError(w, http.StatusNotFound, "unknown post")
+		return
+	}
+
+	if ok, retry := c.limiter.allow(clientIP(r)); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds()+0.5)))
+		writeJSONError(w, http.StatusTooManyRequests, "too many comments, try again later")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCommentBytes)
+	wantsJSON := strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+	var req commentRequest
+	if wantsJSON {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		req = commentRequest{
+			Name:    r.PostFormValue("name"),
+			Body:    r.PostFormValue("body"),
+			Website: r.PostFormValue("website"),
+		}
+	}
+
+	// Bots fill in the hidden honeypot field. Pretend the comment was
+	// accepted so they have no reason to retry.
+	if req.Website != "" {
+		utils.GetLogger().Info("discarded comment caught by honeypot", zap.String("post", id))
+		c.respond(w, r, wantsJSON, id, "")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Body = strings.TrimSpace(req.Body)
+	switch {
+	case req.Name == "" || req.Body == "":
+		writeJSONError(w, http.StatusBadRequest, "name and body are required")
+		return
+	case utf8.RuneCountInString(req.Name) > maxNameLength:
+		writeJSONError(w, http.StatusBadRequest, "name is too long")
+		return
+	case utf8.RuneCountInString(req.Body) > maxBodyLength:
+		writeJSONError(w, http.StatusBadRequest, "comment is too long")
+		return
+	}
+
+	comment, err := c.store.Add(id, req.Name, req.Body)
+	if err != nil {
+		utils.GetLogger().Error("error saving comment", zap.String("post", id), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "could not save comment")
+		return
+	}
+	utils.GetLogger().Info("comment awaiting moderation",
+		zap.String("post", id), zap.String("id", comment.ID))
+	c.respond(w, r, wantsJSON, id, comment.ID)
+}
+
+// respond answers JSON requests with the pending comment and sends browsers
+// submitting the HTML form back to the post
+func (c *commentsAPI) respond(w http.ResponseWriter, r *http.Request, wantsJSON bool, post, id string) {
+	if !wantsJSON {
+		http.Redirect(w, r, "/posts/"+post+".html#comment-pending", http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(commentResponse{ID: id, Status: comments.StatusPending})
+}
+
+// clientIP returns the address of the client. X-Forwarded-For is only
+// trusted when the request comes from a reverse proxy on the same host.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	return host
+}
+
+// rateLimiter allows up to limit events per key in each fixed window
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	now     func() time.Time
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// allow records an event for key and reports whether it is within the limit,
+// and if not, how long until the key's window resets. A limit of zero or
+// less disables rate limiting.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	if l.limit <= 0 || l.window <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	// Drop expired windows so the map does not grow without bound
+	if len(l.windows) > 1024 {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
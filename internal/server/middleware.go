@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/theme"
+	"go.uber.org/zap"
+)
+
+// cacheControl sets the Cache-Control header according to the configured
+// policies. Path rules take precedence; fingerprinted assets never change
+// and can be cached for a long time.
+func cacheControl(policy config.CacheControlConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := cacheControlFor(policy, r.URL.Path); value != "" {
+			w.Header().Set("Cache-Control", value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func cacheControlFor(policy config.CacheControlConfig, urlPath string) string {
+	for _, rule := range policy.Paths {
+		if strings.HasPrefix(urlPath, rule.Prefix) {
+			return rule.Value
+		}
+	}
+	switch {
+	case theme.IsFingerprinted(urlPath):
+		return policy.Fingerprinted
+	case strings.HasSuffix(urlPath, "/") || path.Ext(urlPath) == ".html":
+		return policy.HTML
+	default:
+		return policy.Default
+	}
+}
+
+// statusRecorder captures the status code and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// accessLog logs every request through the zap logger
+func accessLog(logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		logger.Info("request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", rec.status),
+			zap.Int("bytes", rec.bytes),
+			zap.Duration("duration", time.Since(start)),
+			zap.String("remote", r.RemoteAddr),
+			zap.String("userAgent", r.UserAgent()),
+			zap.String("referer", r.Referer()))
+	})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/intothevoid/likho/internal/stats"
+	"github.com/intothevoid/likho/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var botUserAgents = []string{"bot", "crawl", "spider", "slurp", "preview", "curl", "wget"}
+
+// recordPageviews records successful page requests without cookies. Only the
+// path, the referring host and the day are stored, and clients sending DNT or
+// Sec-GPC are not recorded at all.
+func recordPageviews(rec *stats.Recorder, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !isPage(r.URL.Path) || !trackable(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sr := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(sr, r)
+		if sr.status != 0 && sr.status != http.StatusOK && sr.status != http.StatusNotModified {
+			return
+		}
+
+		path := r.URL.Path
+		if strings.HasSuffix(path, "/") {
+			path += "index.html"
+		}
+		pv := stats.Pageview{
+			Day:      time.Now().UTC().Format("2006-01-02"),
+			Path:     path,
+			Referrer: referrerHost(r),
+		}
+		if err := rec.Record(pv); err != nil {
+			utils.GetLogger().Error("error recording pageview", zap.Error(err))
+		}
+	})
+}
+
+// isPage reports whether urlPath is an HTML page rather than an asset
+func isPage(urlPath string) bool {
+	return strings.HasSuffix(urlPath, "/") || strings.HasSuffix(urlPath, ".html")
+}
+
+// trackable reports whether the client agreed to be counted and looks like
+// a browser
+func trackable(r *http.Request) bool {
+	if r.Header.Get("DNT") == "1" || r.Header.Get("Sec-GPC") == "1" {
+		return false
+	}
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return false
+	}
+	for _, bot := range botUserAgents {
+		if strings.Contains(ua, bot) {
+			return false
+		}
+	}
+	return true
+}
+
+// referrerHost returns the host of an external referrer, or "" for direct
+// visits and links within the site
+func referrerHost(r *http.Request) string {
+	ref, err := url.Parse(r.Referer())
+	if err != nil || ref.Hostname() == "" || ref.Host == r.Host {
+		return ""
+	}
+	return strings.TrimPrefix(ref.Hostname(), "www.")
+}
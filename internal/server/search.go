@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/intothevoid/likho/internal/generator"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/search"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+	snippetWords       = 30
+)
+
+// searchAPI answers /api/search?q= from an in-memory index of the posts the
+// generator last parsed
+type searchAPI struct {
+	index atomic.Pointer[search.Index]
+}
+
+type searchResult struct {
+	Title       string   `json:"title"`
+	URL         string   `json:"url"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Date        string   `json:"date"`
+	WordCount   int      `json:"word_count,omitempty"`
+	ReadingTime int      `json:"reading_time,omitempty"`
+	Score       float64  `json:"score"`
+	Snippet     string   `json:"snippet"`
+}
+
+type searchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Results []searchResult `json:"results"`
+}
+
+// update replaces the index with one built from posts
+func (s *searchAPI) update(posts []post.Post) {
+	s.index.Store(search.NewIndex(generator.SearchDocuments(posts)))
+}
+
+func (s *searchAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeJSONError(w, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			writeJSONError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	idx := s.index.Load()
+	if idx == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "search index not ready")
+		return
+	}
+
+	all := idx.Search(query, 0)
+	resp := searchResponse{
+		Query:   query,
+		Total:   len(all),
+		Results: []searchResult{},
+	}
+	for _, res := range all[:min(len(all), limit)] {
+		resp.Results = append(resp.Results, searchResult{
+			Title:       res.Title,
+			URL:         res.URL,
+			Description: res.Description,
+			Tags:        res.Tags,
+			Date:        res.Date.Format("2006-01-02"),
+			WordCount:   res.WordCount,
+			ReadingTime: res.ReadingTime,
+			Score:       res.Score,
+			Snippet:     search.Snippet(res.Body, query, snippetWords),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
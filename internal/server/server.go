@@ -1,30 +1,149 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/intothevoid/likho/internal/config"
 	"github.com/intothevoid/likho/internal/generator"
+	"github.com/intothevoid/likho/internal/stats"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
 
-// Serve starts the HTTP server and serves the generated static files
+// Serve starts the HTTP server and serves the generated static files until
+// the process receives SIGINT or SIGTERM. SIGHUP regenerates the site.
 func Serve(cfg *config.Config) error {
 	logger := utils.GetLogger()
+	api := &searchAPI{}
+	var commentAPI *commentsAPI
+	if selfHostedComments(cfg) {
+		commentAPI = newCommentsAPI(cfg)
+	}
 
-	// Generate the static site
-	if err := generator.Generate(cfg); err != nil {
-		return fmt.Errorf("failed to generate site: %w", err)
+	// Generate the static site and rebuild the in-memory search index
+	regenerate := func() error {
+		site, err := generator.Build(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to generate site: %w", err)
+		}
+		if cfg.Server.SearchAPI {
+			api.update(site.Posts)
+		}
+		if commentAPI != nil {
+			commentAPI.update(site.Posts)
+		}
+		return nil
+	}
+	if err := regenerate(); err != nil {
+		return err
 	}
 
-	// Set up the file server
-	fs := http.FileServer(http.Dir(cfg.Content.OutputDir))
-	http.Handle("/", fs)
+	var views *stats.Recorder
+	if cfg.Server.Pageviews.Enabled {
+		rec, err := stats.NewRecorder(cfg.Server.Pageviews.File)
+		if err != nil {
+			return err
+		}
+		defer rec.Close()
+		views = rec
+	}
 
-	// Start the server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	logger.Info("Server started", zap.String("address", addr))
-	return http.ListenAndServe(addr, nil)
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      newHandler(cfg, api, commentAPI, views),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		ErrorLog:     zap.NewStdLog(logger),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("Server started", zap.String("address", addr))
+		errCh <- srv.ListenAndServe()
+	}()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+wait:
+	for {
+		select {
+		case err := <-errCh:
+			return err
+		case <-hup:
+			logger.Info("regenerating site")
+			if err := regenerate(); err != nil {
+				logger.Error("error regenerating site", zap.Error(err))
+			}
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	logger.Info("shutting down server", zap.Duration("timeout", cfg.Server.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error shutting down server: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info("server stopped")
+	return nil
+}
+
+// selfHostedComments reports whether comments are submitted to this server
+func selfHostedComments(cfg *config.Config) bool {
+	return cfg.Features.Comments && cfg.Comments.Provider == "self-hosted" &&
+		strings.HasPrefix(cfg.Comments.SelfHosted.Endpoint, "/")
+}
+
+// newHandler builds the handler chain serving the output directory, the
+// search API and, when comments is not nil, the comments endpoint. Page
+// requests are recorded to views when it is not nil.
+func newHandler(cfg *config.Config, api *searchAPI, comments *commentsAPI, views *stats.Recorder) http.Handler {
+	root := cfg.Content.OutputDir
+
+	var h http.Handler = fileHandler(root)
+	if cfg.Server.Compress {
+		h = gzipHandler(h)
+	}
+	h = precompressed(root, h)
+	h = cacheControl(cfg.Server.CacheControl, h)
+	if views != nil {
+		h = recordPageviews(views, h)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", h)
+	if cfg.Server.SearchAPI {
+		if cfg.Server.Compress {
+			mux.Handle("/api/search", gzipHandler(api))
+		} else {
+			mux.Handle("/api/search", api)
+		}
+	}
+	if comments != nil {
+		mux.Handle(comments.prefix, comments)
+	}
+
+	if cfg.Server.AccessLog {
+		return accessLog(utils.GetLogger(), mux)
+	}
+	return mux
 }
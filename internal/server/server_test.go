@@ -0,0 +1,291 @@
+package server
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/intothevoid/likho/internal/post"
+	"github.com/intothevoid/likho/internal/stats"
+	"github.com/intothevoid/likho/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSite(t *testing.T) *config.Config {
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html":               "<html><body>" + strings.Repeat("home ", 200) + "</body></html>",
+		"404.html":                 "<html><body>custom not found</body></html>",
+		"css/main.0123456789.css":  "body{color:red}",
+		"images/photo.txt":         "not really a photo",
+		"posts/hello-2024-09.html": "<p>hello</p>",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	return &config.Config{
+		Content: config.ContentConfig{OutputDir: root},
+		Server: config.ServerConfig{
+			Compress: true,
+			CacheControl: config.CacheControlConfig{
+				Fingerprinted: "immutable",
+				HTML:          "no-cache",
+				Default:       "default",
+				Paths:         []config.CacheControlRule{{Prefix: "/images/", Value: "images"}},
+			},
+		},
+	}
+}
+
+func TestHandlerNotFound(t *testing.T) {
+	h := newHandler(newTestSite(t), nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing.html", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "custom not found")
+}
+
+func TestHandlerCacheControl(t *testing.T) {
+	h := newHandler(newTestSite(t), nil, nil, nil)
+
+	tests := map[string]string{
+		"/":                         "no-cache",
+		"/posts/hello-2024-09.html": "no-cache",
+		"/css/main.0123456789.css":  "immutable",
+		"/images/photo.txt":         "images",
+	}
+	for path, want := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, want, rec.Header().Get("Cache-Control"), path)
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	h := newHandler(newTestSite(t), nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts/hello-2024-09.html", nil))
+	tag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, tag)
+	assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+
+	req := httptest.NewRequest(http.MethodGet, "/posts/hello-2024-09.html", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+}
+
+func TestHandlerGzip(t *testing.T) {
+	cfg := newTestSite(t)
+	h := newHandler(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+	zr, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "home home")
+
+	// Precompressed variants are served as they are
+	assert.NoError(t, os.WriteFile(filepath.Join(cfg.Content.OutputDir, "index.html.gz"), []byte("precompressed"), 0644))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "precompressed", rec.Body.String())
+
+	// Small responses are not worth compressing
+	req = httptest.NewRequest(http.MethodGet, "/posts/hello-2024-09.html", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "<p>hello</p>", rec.Body.String())
+}
+
+func TestSearchAPI(t *testing.T) {
+	cfg := newTestSite(t)
+	cfg.Server.SearchAPI = true
+	api := &searchAPI{}
+	h := newHandler(cfg, api, nil, nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q=go", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	api.update([]post.Post{
+		{Title: "Learning Go", Slug: "2024-01-01", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Content: "Go is a **fun** language.", WordCount: 5, ReadingTime: 1},
+		{Title: "Baking bread", Slug: "2024-02-01", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Content: "Flour and water."},
+	})
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q=fun", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp searchResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Total)
+	if assert.Len(t, resp.Results, 1) {
+		assert.Equal(t, "/posts/learning-go-2024-01-01.html", resp.Results[0].URL)
+		assert.Equal(t, "Go is a <mark>fun</mark> language.", resp.Results[0].Snippet)
+		assert.Equal(t, 5, resp.Results[0].WordCount)
+		assert.Equal(t, 1, resp.Results[0].ReadingTime)
+	}
+}
+
+func TestCommentsAPI(t *testing.T) {
+	cfg := newTestSite(t)
+	utils.InitLogger(cfg)
+	cfg.Content.SourceDir = t.TempDir()
+	cfg.Comments.SelfHosted = config.SelfHostedConfig{
+		Endpoint:   "/api/comments",
+		Dir:        "comments",
+		RateLimit:  2,
+		RateWindow: time.Minute,
+	}
+	api := newCommentsAPI(cfg)
+	api.update([]post.Post{{Title: "Learning Go", Slug: "2024-01-01"}})
+	h := newHandler(cfg, nil, api, nil)
+
+	submit := func(path, form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := submit("/api/comments/missing-post", "name=Ann&body=Hi")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = submit("/api/comments/learning-go-2024-01-01", "name=Ann&body=Great+post")
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/posts/learning-go-2024-01-01.html#comment-pending", rec.Header().Get("Location"))
+
+	// The honeypot answers like a real submission but stores nothing
+	rec = submit("/api/comments/learning-go-2024-01-01", "name=Bot&body=Spam&website=spam.example")
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+
+	pending, err := api.store.Pending()
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, "Ann", pending[0].Name)
+		assert.Equal(t, "learning-go-2024-01-01", pending[0].Post)
+	}
+
+	rec = submit("/api/comments/learning-go-2024-01-01", "name=Ann&body=Again")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/comments/learning-go-2024-01-01", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestCommentsAPIJSON(t *testing.T) {
+	cfg := newTestSite(t)
+	utils.InitLogger(cfg)
+	cfg.Content.SourceDir = t.TempDir()
+	cfg.Comments.SelfHosted = config.SelfHostedConfig{Endpoint: "/api/comments", Dir: "comments"}
+	api := newCommentsAPI(cfg)
+	api.update([]post.Post{{Title: "Learning Go", Slug: "2024-01-01"}})
+	h := newHandler(cfg, nil, api, nil)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/comments/learning-go-2024-01-01", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"name": "Ann", "body": "  "}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post(`{"name": "Ann", "body": "Nice"}`)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var resp commentResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "pending", resp.Status)
+	assert.NotEmpty(t, resp.ID)
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		ok, _ := l.allow("a")
+		assert.True(t, ok)
+	}
+	ok, retry := l.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, retry)
+
+	ok, _ = l.allow("b")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	ok, _ = l.allow("a")
+	assert.True(t, ok)
+}
+
+func TestPageviews(t *testing.T) {
+	cfg := newTestSite(t)
+	utils.InitLogger(cfg)
+	path := filepath.Join(t.TempDir(), "pageviews.jsonl")
+	views, err := stats.NewRecorder(path)
+	assert.NoError(t, err)
+	h := newHandler(cfg, nil, nil, views)
+
+	get := func(target string, headers map[string]string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	get("/", map[string]string{"Referer": "https://www.news.example/item?id=1"})
+	get("/posts/hello-2024-09.html", map[string]string{"Referer": "http://example.com/"})
+	get("/css/main.0123456789.css", nil)
+	get("/missing.html", nil)
+	get("/posts/hello-2024-09.html", map[string]string{"DNT": "1"})
+	get("/posts/hello-2024-09.html", map[string]string{"User-Agent": "Googlebot/2.1"})
+	assert.NoError(t, views.Close())
+
+	got, err := stats.Read(path)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "/index.html", got[0].Path)
+		assert.Equal(t, "news.example", got[0].Referrer)
+		assert.Equal(t, "/posts/hello-2024-09.html", got[1].Path)
+		assert.Empty(t, got[1].Referrer)
+		assert.NotContains(t, got[1].Day, ":")
+	}
+}
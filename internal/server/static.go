@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileHandler serves files from root with ETags and a custom 404 page.
+// Directories are served through their index.html and never listed.
+func fileHandler(root string) http.Handler {
+	fs := http.FileServer(http.Dir(root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(name)))
+		if err == nil && info.IsDir() {
+			info, err = os.Stat(filepath.Join(root, filepath.FromSlash(name), "index.html"))
+		}
+		if err != nil || strings.HasPrefix(path.Base(name), ".") {
+			notFound(w, r, root)
+			return
+		}
+
+		w.Header().Set("ETag", etag(info))
+		fs.ServeHTTP(w, r)
+	})
+}
+
+// notFound serves the generated 404.html, falling back to a plain response
+// when the site has none
+func notFound(w http.ResponseWriter, r *http.Request, root string) {
+	data, err := os.ReadFile(filepath.Join(root, "404.html"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusNotFound)
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// etag derives a weak validator from a file's size and modification time.
+// It is weak so that it stays valid for gzip encoded responses.
+func etag(info os.FileInfo) string {
+	return fmt.Sprintf(`W/"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/intothevoid/likho/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// StatsCmd returns the "stats" command used to summarize the pageviews
+// recorded by "likho serve"
+func StatsCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show site statistics",
+	}
+
+	var days, limit int
+	var allPages bool
+	views := &cobra.Command{
+		Use:   "views",
+		Short: "Show the most viewed posts and top referrers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := Read(cfg.Server.Pageviews.File)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no pageviews recorded yet, enable server.pageviews in the config")
+			} else if err != nil {
+				return err
+			}
+			if days > 0 {
+				all = Since(all, time.Now().AddDate(0, 0, 1-days).Format("2006-01-02"))
+			}
+
+			page := func(pv Pageview) string {
+				if allPages || strings.HasPrefix(pv.Path, "/posts/") {
+					return pv.Path
+				}
+				return ""
+			}
+			referrer := func(pv Pageview) string { return pv.Referrer }
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%d views\n\n", len(all))
+			w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "VIEWS\tPAGE")
+			for _, c := range Top(all, page, limit) {
+				fmt.Fprintf(w, "%d\t%s\n", c.Views, c.Key)
+			}
+			fmt.Fprintln(w, "\t")
+			fmt.Fprintln(w, "VIEWS\tREFERRER")
+			for _, c := range Top(all, referrer, limit) {
+				fmt.Fprintf(w, "%d\t%s\n", c.Views, c.Key)
+			}
+			return w.Flush()
+		},
+	}
+	views.Flags().IntVarP(&days, "days", "d", 30, "Only count views from the last n days (0 for all)")
+	views.Flags().IntVarP(&limit, "limit", "n", 10, "Number of pages and referrers to show")
+	views.Flags().BoolVarP(&allPages, "all", "a", false, "Include pages other than posts")
+	cmd.AddCommand(views)
+
+	return cmd
+}
@@ -0,0 +1,117 @@
+// Package stats records and summarizes pageviews collected by "likho serve".
+// Only the path, the referring host and the day of each view are stored, so
+// no cookies or personal data are needed.
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Pageview is a single recorded view of a page
+type Pageview struct {
+	Day      string `json:"day"`
+	Path     string `json:"path"`
+	Referrer string `json:"ref,omitempty"`
+}
+
+// Recorder appends pageviews to a JSON lines file
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewRecorder opens path for appending, creating it if needed
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pageviews file: %v", err)
+	}
+	return &Recorder{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Record appends a pageview to the file
+func (r *Recorder) Record(pv Pageview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(pv)
+}
+
+// Close closes the underlying file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Read returns all pageviews recorded in path
+func Read(path string) ([]Pageview, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var views []Pageview
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var pv Pageview
+		if err := json.Unmarshal(scanner.Bytes(), &pv); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		views = append(views, pv)
+	}
+	return views, scanner.Err()
+}
+
+// Count is the number of views of a path or from a referrer
+type Count struct {
+	Key   string
+	Views int
+}
+
+// Top counts views by key, keeping only views for which key returns a
+// non-empty string, and returns the n most frequent keys. Ties are sorted by
+// key. An n of zero or less returns all keys.
+func Top(views []Pageview, key func(Pageview) string, n int) []Count {
+	counts := make(map[string]int)
+	for _, pv := range views {
+		if k := key(pv); k != "" {
+			counts[k]++
+		}
+	}
+
+	top := make([]Count, 0, len(counts))
+	for k, v := range counts {
+		top = append(top, Count{Key: k, Views: v})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Views != top[j].Views {
+			return top[i].Views > top[j].Views
+		}
+		return top[i].Key < top[j].Key
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Since returns the views recorded on or after day, formatted as 2006-01-02
+func Since(views []Pageview, day string) []Pageview {
+	var out []Pageview
+	for _, pv := range views {
+		if pv.Day >= day {
+			out = append(out, pv)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pageviews.jsonl")
+	rec, err := NewRecorder(path)
+	assert.NoError(t, err)
+
+	views := []Pageview{
+		{Day: "2024-01-01", Path: "/posts/a.html", Referrer: "news.example"},
+		{Day: "2024-01-02", Path: "/posts/b.html"},
+		{Day: "2024-01-03", Path: "/posts/a.html"},
+	}
+	for _, pv := range views {
+		assert.NoError(t, rec.Record(pv))
+	}
+	assert.NoError(t, rec.Close())
+
+	got, err := Read(path)
+	assert.NoError(t, err)
+	assert.Equal(t, views, got)
+}
+
+func TestTop(t *testing.T) {
+	views := []Pageview{
+		{Day: "2024-01-01", Path: "/posts/b.html"},
+		{Day: "2024-01-01", Path: "/posts/a.html", Referrer: "news.example"},
+		{Day: "2024-01-02", Path: "/posts/a.html"},
+		{Day: "2024-01-03", Path: "/posts/c.html", Referrer: "search.example"},
+	}
+	path := func(pv Pageview) string { return pv.Path }
+	referrer := func(pv Pageview) string { return pv.Referrer }
+
+	assert.Equal(t, []Count{{"/posts/a.html", 2}, {"/posts/b.html", 1}}, Top(views, path, 2))
+	assert.Equal(t, []Count{{"news.example", 1}, {"search.example", 1}}, Top(views, referrer, 0))
+	assert.Equal(t, []Count{{"/posts/a.html", 1}, {"/posts/c.html", 1}}, Top(Since(views, "2024-01-02"), path, 0))
+}
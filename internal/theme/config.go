@@ -17,13 +17,39 @@ type ThemeConfig struct {
 	License     string        `yaml:"license"`
 	Assets      ThemeAssets   `yaml:"assets"`
 	Features    ThemeFeatures `yaml:"features"`
+	OGImage     ThemeOGImage  `yaml:"og_image" mapstructure:"og_image"`
 }
 
 // ThemeAssets represents the assets included in a theme
 type ThemeAssets struct {
-	CSS    []string `yaml:"css"`
-	JS     []string `yaml:"js"`
-	Images []string `yaml:"images"`
+	CSS     []string      `yaml:"css"`
+	JS      []string      `yaml:"js"`
+	Images  []string      `yaml:"images"`
+	Bundles []ThemeBundle `yaml:"bundles"`
+}
+
+// ThemeBundle combines several CSS or JS files into a single asset
+type ThemeBundle struct {
+	Name  string   `yaml:"name"`
+	Files []string `yaml:"files"`
+}
+
+// ThemeOGImage represents the layout of the Open Graph preview images
+// generated for posts. Colours are hex values; an empty background uses the
+// site's primary colour. Fonts are TrueType or OpenType files relative to the
+// theme directory and default to the Go fonts.
+type ThemeOGImage struct {
+	Background string `yaml:"background" mapstructure:"background"`
+	Foreground string `yaml:"foreground" mapstructure:"foreground"`
+	Accent     string `yaml:"accent" mapstructure:"accent"`
+	TitleFont  string `yaml:"title_font" mapstructure:"title_font"`
+	TextFont   string `yaml:"text_font" mapstructure:"text_font"`
+	TitleSize  int    `yaml:"title_size" mapstructure:"title_size"`
+	TextSize   int    `yaml:"text_size" mapstructure:"text_size"`
+	Padding    int    `yaml:"padding" mapstructure:"padding"`
+	MaxLines   int    `yaml:"max_lines" mapstructure:"max_lines"`
+	ShowSite   bool   `yaml:"show_site" mapstructure:"show_site"`
+	ShowDate   bool   `yaml:"show_date" mapstructure:"show_date"`
 }
 
 // ThemeFeatures represents the features supported by a theme
@@ -40,6 +66,15 @@ func LoadThemeConfig(themePath string) (*ThemeConfig, error) {
 	v.SetConfigType("yaml")
 	v.AddConfigPath(themePath)
 
+	v.SetDefault("og_image.foreground", "#ffffff")
+	v.SetDefault("og_image.accent", "#ffffff")
+	v.SetDefault("og_image.title_size", 72)
+	v.SetDefault("og_image.text_size", 32)
+	v.SetDefault("og_image.padding", 80)
+	v.SetDefault("og_image.max_lines", 4)
+	v.SetDefault("og_image.show_site", true)
+	v.SetDefault("og_image.show_date", true)
+
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
@@ -65,6 +100,13 @@ func LoadThemeConfig(themePath string) (*ThemeConfig, error) {
 		img = strings.TrimPrefix(img, "static/")
 		config.Assets.Images[i] = img
 	}
+	for i, bundle := range config.Assets.Bundles {
+		bundle.Name = strings.TrimPrefix(bundle.Name, "static/")
+		for j, file := range bundle.Files {
+			bundle.Files[j] = strings.TrimPrefix(file, "static/")
+		}
+		config.Assets.Bundles[i] = bundle
+	}
 
 	return &config, nil
 }
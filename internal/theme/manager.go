@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/intothevoid/likho/internal/assets"
 	"github.com/intothevoid/likho/pkg/utils"
 	"go.uber.org/zap"
 )
@@ -15,6 +16,7 @@ type ThemeManager struct {
 	config     *ThemeConfig
 	themePath  string
 	outputPath string
+	assets     map[string]Asset
 }
 
 // NewThemeManager creates a new theme manager
@@ -50,30 +52,9 @@ func (tm *ThemeManager) CopyAssets() error {
 		}
 	}
 
-	// Copy CSS files
-	for _, css := range tm.config.Assets.CSS {
-		srcPath := filepath.Join(tm.themePath, "static", css)
-		dstPath := filepath.Join(tm.outputPath, css)
-		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-			tm.logger.Warn("CSS file not found, skipping", zap.String("path", srcPath))
-			continue
-		}
-		if err := copyFile(srcPath, dstPath); err != nil {
-			return fmt.Errorf("failed to copy CSS file %s: %v", css, err)
-		}
-	}
-
-	// Copy JS files
-	for _, js := range tm.config.Assets.JS {
-		srcPath := filepath.Join(tm.themePath, "static", js)
-		dstPath := filepath.Join(tm.outputPath, js)
-		if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-			tm.logger.Warn("JS file not found, skipping", zap.String("path", srcPath))
-			continue
-		}
-		if err := copyFile(srcPath, dstPath); err != nil {
-			return fmt.Errorf("failed to copy JS file %s: %v", js, err)
-		}
+	// Bundle, minify and fingerprint CSS and JS files
+	if err := tm.buildAssets(); err != nil {
+		return err
 	}
 
 	// Copy image files
@@ -89,6 +70,64 @@ func (tm *ThemeManager) CopyAssets() error {
 		}
 	}
 
+	return tm.copyVendorAssets()
+}
+
+// copyVendorAssets copies the vendored front-end libraries to the output
+// directory. Files under the theme's static/vendor directory are copied last
+// so a theme can override or add to the libraries embedded in the binary.
+func (tm *ThemeManager) copyVendorAssets() error {
+	vendorDir := filepath.Join(tm.outputPath, "vendor")
+	themeVendorDir := filepath.Join(tm.themePath, "static", "vendor")
+
+	files, err := assets.Files()
+	if err != nil {
+		return fmt.Errorf("failed to list vendored assets: %v", err)
+	}
+	for _, file := range files {
+		data, err := assets.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read vendored file %s: %v", file, err)
+		}
+		dstPath := filepath.Join(vendorDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(dstPath), err)
+		}
+		if err := os.WriteFile(dstPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write vendored file %s: %v", file, err)
+		}
+	}
+
+	if _, err := os.Stat(themeVendorDir); err == nil {
+		err = filepath.Walk(themeVendorDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(themeVendorDir, path)
+			if err != nil {
+				return err
+			}
+			dstPath := filepath.Join(vendorDir, relPath)
+			if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %v", filepath.Dir(dstPath), err)
+			}
+			return copyFile(path, dstPath)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy theme vendored files: %v", err)
+		}
+	}
+
+	// Pages link to every library file, so one that is neither embedded nor
+	// provided by the theme would break highlighting or diagrams
+	for _, lib := range assets.Libraries() {
+		for _, file := range append(lib.Styles, lib.Scripts...) {
+			if _, err := os.Stat(filepath.Join(vendorDir, filepath.FromSlash(file))); os.IsNotExist(err) {
+				return fmt.Errorf("vendored %s file %s not found, run scripts/vendor_assets.sh", lib.Name, file)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -102,6 +141,16 @@ func (tm *ThemeManager) GetFeatures() ThemeFeatures {
 	return tm.config.Features
 }
 
+// GetOGImage returns the layout of the theme's Open Graph preview images
+func (tm *ThemeManager) GetOGImage() ThemeOGImage {
+	return tm.config.OGImage
+}
+
+// GetPath returns the path to the theme directory
+func (tm *ThemeManager) GetPath() string {
+	return tm.themePath
+}
+
 // copyFile copies a file from src to dst
 func copyFile(src, dst string) error {
 	input, err := os.ReadFile(src)
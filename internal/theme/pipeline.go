@@ -0,0 +1,149 @@
+package theme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
+	"go.uber.org/zap"
+)
+
+// Asset is a processed CSS or JS file as referenced from templates
+type Asset struct {
+	// URL is the site-relative URL of the fingerprinted file
+	URL string
+	// Integrity is the Subresource Integrity hash of the file
+	Integrity string
+}
+
+// fingerprintRe matches the content hash inserted into processed asset names
+var fingerprintRe = regexp.MustCompile(`\.[0-9a-f]{10}(\.(?:css|js))$`)
+
+// IsFingerprinted reports whether a file name carries a content hash added by
+// the asset pipeline
+func IsFingerprinted(name string) bool {
+	return fingerprintRe.MatchString(name)
+}
+
+// buildAssets concatenates, minifies and fingerprints the theme's CSS and JS.
+// Files listed in a bundle are combined under the bundle's name; every other
+// CSS or JS file is processed on its own.
+func (tm *ThemeManager) buildAssets() error {
+	m := minify.New()
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+
+	bundled := make(map[string]bool)
+	var groups []ThemeBundle
+	for _, b := range tm.config.Assets.Bundles {
+		for _, f := range b.Files {
+			bundled[f] = true
+		}
+		groups = append(groups, b)
+	}
+	for _, f := range append(append([]string{}, tm.config.Assets.CSS...), tm.config.Assets.JS...) {
+		if !bundled[f] {
+			groups = append(groups, ThemeBundle{Name: f, Files: []string{f}})
+		}
+	}
+
+	tm.assets = make(map[string]Asset)
+	for _, g := range groups {
+		var mediaType string
+		switch path.Ext(g.Name) {
+		case ".css":
+			mediaType = "text/css"
+		case ".js":
+			mediaType = "application/javascript"
+		default:
+			return fmt.Errorf("unsupported asset type: %s", g.Name)
+		}
+
+		var buf bytes.Buffer
+		for _, f := range g.Files {
+			srcPath := filepath.Join(tm.themePath, "static", filepath.FromSlash(f))
+			data, err := os.ReadFile(srcPath)
+			if err != nil {
+				return fmt.Errorf("failed to read asset %s: %v", f, err)
+			}
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+		if buf.Len() == 0 {
+			continue
+		}
+
+		out, err := m.Bytes(mediaType, buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to minify asset %s: %v", g.Name, err)
+		}
+
+		if err := tm.writeAsset(g.Name, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeAsset writes a processed asset under its fingerprinted name only, so
+// that no copy can be served under a name that outlives its content
+func (tm *ThemeManager) writeAsset(name string, data []byte) error {
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	hashedName := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:10] + ext
+
+	dir := filepath.Join(tm.outputPath, filepath.FromSlash(path.Dir(name)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", dir, err)
+	}
+
+	// Remove the copies left over from previous builds, fingerprinted or
+	// written under the plain name by older versions
+	plain := filepath.Join(tm.outputPath, filepath.FromSlash(name))
+	stale, err := filepath.Glob(filepath.Join(dir, strings.TrimSuffix(path.Base(name), ext)+".*"+ext))
+	if err != nil {
+		return err
+	}
+	for _, f := range append(stale, plain) {
+		if f != plain && !IsFingerprinted(f) {
+			continue
+		}
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale asset %s: %v", f, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(tm.outputPath, filepath.FromSlash(hashedName)), data, 0644); err != nil {
+		return fmt.Errorf("failed to write asset %s: %v", hashedName, err)
+	}
+
+	integrity := sha512.Sum384(data)
+	tm.assets[name] = Asset{
+		URL:       "/" + hashedName,
+		Integrity: "sha384-" + base64.StdEncoding.EncodeToString(integrity[:]),
+	}
+	tm.logger.Debug("asset built", zap.String("name", name), zap.String("url", tm.assets[name].URL))
+	return nil
+}
+
+// Asset returns the processed asset for a logical name such as "css/main.css".
+// CopyAssets must have been called first.
+func (tm *ThemeManager) Asset(name string) (Asset, error) {
+	a, ok := tm.assets[strings.TrimPrefix(name, "/")]
+	if !ok {
+		return Asset{}, fmt.Errorf("unknown asset: %s", name)
+	}
+	return a, nil
+}
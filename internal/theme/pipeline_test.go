@@ -0,0 +1,86 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBuildAssets(t *testing.T) {
+	themeDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	files := map[string]string{
+		"static/css/reset.css": "html {\n  margin: 0;\n}\n",
+		"static/css/main.css":  "body {\n  color: #ffffff;\n}\n",
+		"static/js/app.js":     "function hello() {\n  return 1 + 1;\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(themeDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	tm := &ThemeManager{
+		logger: zap.NewNop(),
+		config: &ThemeConfig{
+			Assets: ThemeAssets{
+				CSS: []string{"css/reset.css", "css/main.css"},
+				JS:  []string{"js/app.js"},
+				Bundles: []ThemeBundle{
+					{Name: "css/site.css", Files: []string{"css/reset.css", "css/main.css"}},
+				},
+			},
+		},
+		themePath:  themeDir,
+		outputPath: outputDir,
+	}
+
+	assert.NoError(t, tm.buildAssets())
+
+	site, err := tm.Asset("css/site.css")
+	assert.NoError(t, err)
+	assert.True(t, IsFingerprinted(site.URL), site.URL)
+	assert.True(t, strings.HasPrefix(site.Integrity, "sha384-"))
+
+	data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(strings.TrimPrefix(site.URL, "/"))))
+	assert.NoError(t, err)
+	assert.Equal(t, "html{margin:0}body{color:#fff}", string(data))
+
+	// Only the fingerprinted file is written
+	_, err = os.Stat(filepath.Join(outputDir, "css", "site.css"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Bundled files are not published on their own
+	_, err = tm.Asset("css/main.css")
+	assert.Error(t, err)
+
+	_, err = tm.Asset("/js/app.js")
+	assert.NoError(t, err)
+
+	// Rebuilding with changed content replaces the old fingerprinted file
+	assert.NoError(t, os.WriteFile(filepath.Join(themeDir, "static/css/main.css"), []byte("body { color: red; }"), 0644))
+	assert.NoError(t, tm.buildAssets())
+	matches, err := filepath.Glob(filepath.Join(outputDir, "css", "site.*.css"))
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+}
+
+func TestBuildAssetsMissingFile(t *testing.T) {
+	tm := &ThemeManager{
+		logger: zap.NewNop(),
+		config: &ThemeConfig{
+			Assets: ThemeAssets{CSS: []string{"css/missing.css"}},
+		},
+		themePath:  t.TempDir(),
+		outputPath: t.TempDir(),
+	}
+
+	err := tm.buildAssets()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "css/missing.css")
+}
@@ -0,0 +1,32 @@
+#!/bin/sh
+# Updates the front-end libraries committed under internal/assets/vendor,
+# which are embedded into the likho binary. Builds do not run it; change the
+# versions below and commit the fetched files to upgrade a library.
+set -e
+
+PRISM_VERSION="1.29.0"
+MERMAID_VERSION="10.9.1"
+
+ROOT="$(cd "$(dirname "$0")/.." && pwd)"
+VENDOR="$ROOT/internal/assets/vendor"
+TMP="$(mktemp -d)"
+trap 'rm -rf "$TMP"' EXIT
+
+echo "Fetching prismjs@$PRISM_VERSION"
+curl -fsSL "https://registry.npmjs.org/prismjs/-/prismjs-$PRISM_VERSION.tgz" | tar -xz -C "$TMP"
+rm -rf "$VENDOR/prism"
+mkdir -p "$VENDOR/prism/themes" "$VENDOR/prism/plugins/autoloader" "$VENDOR/prism/components"
+cp "$TMP/package/themes/"*.min.css "$VENDOR/prism/themes/"
+cp "$TMP/package/plugins/autoloader/prism-autoloader.min.js" "$VENDOR/prism/plugins/autoloader/"
+cp "$TMP/package/components/"*.min.js "$VENDOR/prism/components/"
+cp "$TMP/package/LICENSE" "$VENDOR/prism/LICENSE"
+rm -rf "$TMP/package"
+
+echo "Fetching mermaid@$MERMAID_VERSION"
+curl -fsSL "https://registry.npmjs.org/mermaid/-/mermaid-$MERMAID_VERSION.tgz" | tar -xz -C "$TMP"
+rm -rf "$VENDOR/mermaid"
+mkdir -p "$VENDOR/mermaid"
+cp "$TMP/package/dist/mermaid.min.js" "$VENDOR/mermaid/"
+cp "$TMP/package/LICENSE" "$VENDOR/mermaid/LICENSE"
+
+echo "Vendored assets written to $VENDOR"